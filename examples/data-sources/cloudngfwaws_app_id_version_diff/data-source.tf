@@ -0,0 +1,10 @@
+data "cloudngfwaws_app_id_version_diff" "example" {
+  from_version   = "8595-7473"
+  to_version     = "8601-7502"
+  detect_renames = true
+  rulestack      = "my-rulestack"
+}
+
+output "affected_rules" {
+  value = data.cloudngfwaws_app_id_version_diff.example.affected_rules
+}
@@ -1 +1,6 @@
 data "cloudngfwaws_app_id_versions" "example" {}
+
+# The latest AppId version minus 2.
+output "pinned" {
+  value = data.cloudngfwaws_app_id_versions.example.details[2].version
+}
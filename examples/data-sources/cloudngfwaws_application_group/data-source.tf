@@ -0,0 +1,28 @@
+data "cloudngfwaws_application_group" "file_sharing" {
+  name         = "file-sharing"
+  applications = ["dropbox"]
+
+  filter {
+    categories    = ["general-internet"]
+    subcategories = ["file-sharing"]
+    risks         = [4, 5]
+  }
+
+  exclude = ["bittorrent"]
+}
+
+resource "cloudngfwaws_security_rule" "example" {
+  rulestack    = "my-rulestack"
+  rule_list    = "LocalRule"
+  priority     = 3
+  name         = "block file sharing"
+  action       = "DenyResetBoth"
+  applications = data.cloudngfwaws_application_group.file_sharing.members
+  source {
+    cidrs = ["any"]
+  }
+  destination {
+    cidrs = ["any"]
+  }
+  category {}
+}
@@ -1 +1,3 @@
-data "cloudngfwaws_country" "example" {}
+data "cloudngfwaws_country" "example" {
+  filter_codes = ["US", "CA", "MX"]
+}
@@ -1,3 +1,10 @@
 data "cloudngfwaws_ngfw" "example" {
   name = "example-instance"
 }
+
+data "cloudngfwaws_ngfw" "by_vpc" {
+  vpc_id = "vpc-0123456789abcdef0"
+  tags = {
+    Environment = "production"
+  }
+}
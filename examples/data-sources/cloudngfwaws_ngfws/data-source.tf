@@ -1 +1,12 @@
 data "cloudngfwaws_ngfws" "example" {}
+
+data "cloudngfwaws_ngfws" "prod" {
+  name_prefix       = "prod-"
+  firewall_statuses = ["CREATE_COMPLETE", "UPDATE_COMPLETE"]
+  endpoint_mode     = "CustomerManaged"
+  describe          = true
+
+  tags = {
+    Environment = "production"
+  }
+}
@@ -0,0 +1,11 @@
+ephemeral "cloudngfwaws_api_token" "example" {
+  role_type = "Firewall"
+}
+
+provider "restapi" {
+  uri = "https://api.us-east-1.aws.cloudngfw.paloaltonetworks.com"
+  headers = {
+    Authorization = ephemeral.cloudngfwaws_api_token.example.jwt
+    x-api-key     = ephemeral.cloudngfwaws_api_token.example.subscription_key
+  }
+}
@@ -0,0 +1,3 @@
+output "security_rule_id" {
+  value = provider::cloudngfwaws::build_security_rule_id("Local", "terraform-rulestack", "LocalRule", 3)
+}
@@ -0,0 +1,9 @@
+resource "cloudngfwaws_prefix_list" "example" {
+  rulestack = cloudngfwaws_rulestack.r.name
+  name      = "tf-prefix-list"
+  prefix_list = provider::cloudngfwaws::normalize_cidrs([
+    "10.1.5.7/24",
+    "192.168.0.0/16",
+    "10.1.5.0/24",
+  ])
+}
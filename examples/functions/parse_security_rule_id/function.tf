@@ -0,0 +1,7 @@
+locals {
+  rule = provider::cloudngfwaws::parse_security_rule_id("Local:terraform-rulestack:LocalRule:3")
+}
+
+output "rulestack" {
+  value = local.rule.rulestack
+}
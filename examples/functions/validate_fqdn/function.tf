@@ -0,0 +1,8 @@
+variable "fqdns" {
+  type = list(string)
+
+  validation {
+    condition     = alltrue([for x in var.fqdns : provider::cloudngfwaws::validate_fqdn(x)])
+    error_message = "All FQDNs must be valid, with wildcards only as the leftmost label."
+  }
+}
@@ -8,6 +8,11 @@ resource "cloudngfwaws_ngfw" "example" {
   tags = {
     Foo = "bar"
   }
+
+  wait_for {
+    firewall_status  = ["CREATE_COMPLETE", "UPDATE_COMPLETE"]
+    rulestack_status = ["Success"]
+  }
 }
 
 resource "cloudngfwaws_commit_rulestack" "rs" {
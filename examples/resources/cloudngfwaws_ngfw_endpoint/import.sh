@@ -0,0 +1,2 @@
+# import name is <firewall_id>:<subnet_id>
+terraform import cloudngfwaws_ngfw_endpoint.example fw-0123456789:subnet-0123456789abcdef0
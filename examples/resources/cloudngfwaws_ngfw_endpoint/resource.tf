@@ -0,0 +1,27 @@
+# One endpoint per VPC sharing the same NGFW.
+resource "cloudngfwaws_ngfw_endpoint" "example" {
+  for_each = aws_subnet.example
+
+  firewall_id = cloudngfwaws_ngfw.example.firewall_id
+  subnet_id   = each.value.id
+  vpc_id      = each.value.vpc_id
+  mode        = "CustomerManaged"
+}
+
+resource "cloudngfwaws_ngfw" "example" {
+  name          = "example-instance"
+  endpoint_mode = "CustomerManaged"
+  multi_vpc     = true
+  az_list       = ["use1-az1"]
+}
+
+resource "aws_subnet" "example" {
+  for_each = {
+    app = "vpc-0123456789abcdef0"
+    db  = "vpc-0fedcba9876543210"
+  }
+
+  vpc_id            = each.value
+  cidr_block        = "172.16.10.0/24"
+  availability_zone = "us-east-1a"
+}
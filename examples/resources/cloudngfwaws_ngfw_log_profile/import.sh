@@ -1,2 +1,2 @@
-# import name is <account_id>:<ngfw>
-terraform import cloudngfwaws_ngfw_log_profile.example 12345678:example-instance
+# import name is the firewall ID or the name of the NGFW
+terraform import cloudngfwaws_ngfw_log_profile.example example-instance
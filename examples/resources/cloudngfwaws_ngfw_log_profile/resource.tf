@@ -1,10 +1,10 @@
 resource "cloudngfwaws_ngfw_log_profile" "example" {
   firewall_id = cloudngfwaws_ngfw.x.firewall_id
   log_config {
-    log_destination      = "S3"
-    log_destination_type = "my-s3-bucket"
+    log_destination      = "my-s3-bucket"
+    log_destination_type = "S3"
     log_type             = ["TRAFFIC", "THREAT"]
-    account_id           = ["123456789012"]
+    account_id           = "123456789012"
   }
 }
 
@@ -0,0 +1,2 @@
+# import name is <firewall_id>
+terraform import cloudngfwaws_ngfw_rulestack_association.example fw-0123456789
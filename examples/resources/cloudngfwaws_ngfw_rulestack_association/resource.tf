@@ -0,0 +1,28 @@
+# Blue/green rulestacks: build and commit the new rulestack alongside the live
+# one, then switch "active". If the new rulestack fails to commit on the NGFW,
+# the previous one is associated again.
+variable "active" {
+  type    = string
+  default = "blue"
+}
+
+resource "cloudngfwaws_ngfw_rulestack_association" "example" {
+  firewall_id = cloudngfwaws_ngfw.example.firewall_id
+  rulestack   = cloudngfwaws_commit_rulestack.example[var.active].rulestack
+}
+
+resource "cloudngfwaws_commit_rulestack" "example" {
+  for_each = toset(["blue", "green"])
+
+  rulestack = "my-rulestack-${each.key}"
+}
+
+resource "cloudngfwaws_ngfw" "example" {
+  name        = "example-instance"
+  description = "Example description"
+  az_list     = ["use1-az1"]
+
+  lifecycle {
+    ignore_changes = [rulestack]
+  }
+}
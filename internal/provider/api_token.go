@@ -0,0 +1,187 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	fwdiag "github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	eschema "github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/ngfw/aws"
+)
+
+// Valid values for role_type within the API token ephemeral resource.
+const (
+	FirewallRoleType        = "Firewall"
+	RulestackRoleType       = "Rulestack"
+	GlobalRulestackRoleType = "GlobalRulestack"
+	AccountAdminRoleType    = "AccountAdmin"
+)
+
+// Ephemeral resource.
+type apiTokenEphemeralResource struct {
+	client *lazyAwsClient
+}
+
+type apiTokenModel struct {
+	RoleType        types.String `tfsdk:"role_type"`
+	Jwt             types.String `tfsdk:"jwt"`
+	SubscriptionKey types.String `tfsdk:"subscription_key"`
+	ExpiresAt       types.String `tfsdk:"expires_at"`
+}
+
+var (
+	_ ephemeral.EphemeralResourceWithConfigure      = &apiTokenEphemeralResource{}
+	_ ephemeral.EphemeralResourceWithValidateConfig = &apiTokenEphemeralResource{}
+)
+
+func newApiTokenEphemeralResource() ephemeral.EphemeralResource {
+	return &apiTokenEphemeralResource{}
+}
+
+func (r *apiTokenEphemeralResource) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_api_token"
+}
+
+func (r *apiTokenEphemeralResource) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	roleTypes := []string{FirewallRoleType, RulestackRoleType, GlobalRulestackRoleType, AccountAdminRoleType}
+
+	resp.Schema = eschema.Schema{
+		MarkdownDescription: "Ephemeral resource for retrieving a short-lived Cloud NGFW API token, which is never persisted to state. The token is not renewed, so it is only valid until `expires_at` and should only be used within a single run.",
+
+		Attributes: map[string]eschema.Attribute{
+			"role_type": eschema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: addStringInSliceValidation("The admin role to retrieve the JWT for. This uses the matching ARN from the provider config.", roleTypes),
+			},
+			"jwt": eschema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "The JWT.",
+			},
+			"subscription_key": eschema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "The subscription key to send as the `x-api-key` header along with the JWT.",
+			},
+			"expires_at": eschema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "When the JWT expires, in RFC 3339 format.",
+			},
+		},
+	}
+}
+
+func (r *apiTokenEphemeralResource) Configure(ctx context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	con, ok := req.ProviderData.(*lazyAwsClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected provider data",
+			fmt.Sprintf("Expected *lazyAwsClient, got %T.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = con
+}
+
+func (r *apiTokenEphemeralResource) ValidateConfig(ctx context.Context, req ephemeral.ValidateConfigRequest, resp *ephemeral.ValidateConfigResponse) {
+	var o apiTokenModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &o)...)
+	if resp.Diagnostics.HasError() || o.RoleType.IsNull() || o.RoleType.IsUnknown() {
+		return
+	}
+
+	switch o.RoleType.ValueString() {
+	case FirewallRoleType, RulestackRoleType, GlobalRulestackRoleType, AccountAdminRoleType:
+	default:
+		resp.Diagnostics.AddError(
+			"Invalid role_type",
+			fmt.Sprintf("Unknown role type %q.", o.RoleType.ValueString()),
+		)
+	}
+}
+
+func (r *apiTokenEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var o apiTokenModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &o)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	roleType := o.RoleType.ValueString()
+	tflog.Info(
+		ctx, "open api token",
+		map[string]interface{}{
+			"role_type": roleType,
+		},
+	)
+
+	var con *aws.Client
+	if r.client != nil {
+		var diags fwdiag.Diagnostics
+		var err error
+		con, diags, err = r.client.get(ctx)
+		resp.Diagnostics.Append(diags...)
+		if err != nil {
+			resp.Diagnostics.AddError("Error configuring the provider", err.Error())
+			return
+		}
+	}
+
+	jwt, key, expires, err := refreshApiToken(ctx, con, roleType)
+	if err != nil {
+		resp.Diagnostics.AddError("Error retrieving the API token", err.Error())
+		return
+	}
+
+	o.Jwt = types.StringValue(jwt)
+	o.SubscriptionKey = types.StringValue(key)
+	o.ExpiresAt = types.StringValue(expires.Format(time.RFC3339))
+
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &o)...)
+}
+
+// refreshApiToken refreshes (if needed) and returns the JWT, subscription key,
+// and JWT expiration time for the given role type.
+func refreshApiToken(ctx context.Context, con *aws.Client, roleType string) (string, string, time.Time, error) {
+	if con == nil {
+		return "", "", time.Time{}, fmt.Errorf("the provider has not been configured")
+	}
+
+	var err error
+	var jwt, key string
+	var expires time.Time
+	switch roleType {
+	case FirewallRoleType:
+		err = con.RefreshFirewallAdminJwt(ctx)
+		jwt, key, expires = con.FirewallAdminJwt, con.FirewallSubscriptionKey, con.FirewallAdminJwtExpTime
+	case RulestackRoleType:
+		err = con.RefreshRulestackAdminJwt(ctx)
+		jwt, key, expires = con.RulestackAdminJwt, con.RulestackSubscriptionKey, con.RulestackAdminJwtExpTime
+	case GlobalRulestackRoleType:
+		err = con.RefreshGlobalRulestackAdminJwt(ctx)
+		jwt, key, expires = con.GlobalRulestackAdminJwt, con.GlobalRulestackSubscriptionKey, con.GlobalRulestackAdminJwtExpTime
+	case AccountAdminRoleType:
+		err = con.RefreshAccountAdminJwt(ctx)
+		jwt, key, expires = con.AccountAdminJwt, con.AccountAdminSubscriptionKey, con.AccountAdminJwtExpTime
+	default:
+		return "", "", time.Time{}, fmt.Errorf("unknown role type %q", roleType)
+	}
+
+	if err != nil {
+		return "", "", time.Time{}, err
+	}
+	if jwt == "" {
+		return "", "", time.Time{}, fmt.Errorf("no %s JWT was retrieved, check that the provider has an ARN configured for this role", roleType)
+	}
+
+	return jwt, key, expires, nil
+}
@@ -0,0 +1,134 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/ngfw/aws"
+)
+
+func TestApiTokenOpen(t *testing.T) {
+	ctx := context.Background()
+	expires := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	// The JWTs are still valid, so nothing is refreshed from the API.
+	con := &aws.Client{
+		FirewallAdminJwt:        "fw-jwt",
+		FirewallSubscriptionKey: "fw-key",
+		FirewallAdminJwtExpTime: expires,
+	}
+
+	table := []struct {
+		name     string
+		client   *lazyAwsClient
+		roleType string
+		jwt      string
+		key      string
+		errors   bool
+	}{
+		{"firewall", configuredAwsClient(con), FirewallRoleType, "fw-jwt", "fw-key", false},
+		{"role without a jwt", configuredAwsClient(&aws.Client{AccountAdminJwtExpTime: expires}), AccountAdminRoleType, "", "", true},
+		{"unknown role", configuredAwsClient(con), "Admin", "", "", true},
+		{"unconfigured provider", nil, FirewallRoleType, "", "", true},
+	}
+
+	for _, tc := range table {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &apiTokenEphemeralResource{client: tc.client}
+			req, resp := testApiTokenOpenRequest(ctx, t, r, tc.roleType)
+
+			r.Open(ctx, req, resp)
+			if tc.errors {
+				if !resp.Diagnostics.HasError() {
+					t.Fatalf("expected an error")
+				}
+				return
+			}
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+
+			var o apiTokenModel
+			if diags := resp.Result.Get(ctx, &o); diags.HasError() {
+				t.Fatalf("error reading the result: %v", diags)
+			}
+			if o.Jwt.ValueString() != tc.jwt {
+				t.Errorf("jwt: expected %q, got %q", tc.jwt, o.Jwt.ValueString())
+			}
+			if o.SubscriptionKey.ValueString() != tc.key {
+				t.Errorf("subscription_key: expected %q, got %q", tc.key, o.SubscriptionKey.ValueString())
+			}
+			if o.ExpiresAt.ValueString() != expires.Format(time.RFC3339) {
+				t.Errorf("expires_at: expected %q, got %q", expires.Format(time.RFC3339), o.ExpiresAt.ValueString())
+			}
+			if !resp.RenewAt.IsZero() {
+				t.Errorf("expected no renewal, got %s", resp.RenewAt)
+			}
+		})
+	}
+}
+
+func TestApiTokenValidateConfig(t *testing.T) {
+	ctx := context.Background()
+	r := &apiTokenEphemeralResource{}
+
+	table := []struct {
+		roleType string
+		errors   bool
+	}{
+		{FirewallRoleType, false},
+		{RulestackRoleType, false},
+		{GlobalRulestackRoleType, false},
+		{AccountAdminRoleType, false},
+		{"firewall", true},
+		{"", true},
+	}
+
+	for _, tc := range table {
+		req, _ := testApiTokenOpenRequest(ctx, t, r, tc.roleType)
+		resp := &ephemeral.ValidateConfigResponse{}
+		r.ValidateConfig(ctx, ephemeral.ValidateConfigRequest{Config: req.Config}, resp)
+		if resp.Diagnostics.HasError() != tc.errors {
+			t.Errorf("role_type %q: expected error %t, got %v", tc.roleType, tc.errors, resp.Diagnostics)
+		}
+	}
+}
+
+// configuredAwsClient returns a lazyAwsClient that has already been set up
+// with the given client.
+func configuredAwsClient(con *aws.Client) *lazyAwsClient {
+	c := &lazyAwsClient{con: con}
+	c.once.Do(func() {})
+	return c
+}
+
+func testApiTokenOpenRequest(ctx context.Context, t *testing.T, r *apiTokenEphemeralResource, roleType string) (ephemeral.OpenRequest, *ephemeral.OpenResponse) {
+	t.Helper()
+
+	sresp := &ephemeral.SchemaResponse{}
+	r.Schema(ctx, ephemeral.SchemaRequest{}, sresp)
+	if sresp.Diagnostics.HasError() {
+		t.Fatalf("schema error: %v", sresp.Diagnostics)
+	}
+	typ := sresp.Schema.Type().TerraformType(ctx)
+
+	raw := tftypes.NewValue(typ, map[string]tftypes.Value{
+		"role_type":        tftypes.NewValue(tftypes.String, roleType),
+		"jwt":              tftypes.NewValue(tftypes.String, nil),
+		"subscription_key": tftypes.NewValue(tftypes.String, nil),
+		"expires_at":       tftypes.NewValue(tftypes.String, nil),
+	})
+
+	req := ephemeral.OpenRequest{
+		Config: tfsdk.Config{Schema: sresp.Schema, Raw: raw},
+	}
+	resp := &ephemeral.OpenResponse{
+		Result: tfsdk.EphemeralResultData{Schema: sresp.Schema, Raw: tftypes.NewValue(typ, nil)},
+	}
+
+	return req, resp
+}
@@ -3,14 +3,15 @@ package provider
 import (
 	"github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api"
 	"github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api/appid"
+	"github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api/security"
+	permissions "github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/ngfw/aws"
 
 	"context"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
-	// "github.com/paloaltonetworks/cloud-ngfw-aws-go/v2"
-	// "github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/appid"
-
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -27,60 +28,121 @@ func dataSourceAppIdVersions() *schema.Resource {
 			"max_results": {
 				Type:        schema.TypeInt,
 				Optional:    true,
-				Description: "Max number of results.",
+				Description: "Max number of results per page. All pages are retrieved.",
 				Default:     100,
 			},
 			"token": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				Description: "Pagination token.",
+				Description: "Pagination token to start from.",
+				Deprecated:  "All pages are now retrieved automatically, so this param is no longer needed.",
 			},
 			"next_token": {
 				Type:        schema.TypeString,
 				Computed:    true,
 				Description: "Token for the next page of results.",
+				Deprecated:  "All pages are now retrieved automatically, so this attribute is always empty.",
 			},
 			"versions": {
 				Type:        schema.TypeList,
 				Computed:    true,
-				Description: "List of AppId versions.",
+				Description: "List of AppId versions, sorted oldest to newest.",
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
 			},
+			"latest": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The latest AppId version.",
+			},
+			"details": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Per version metadata, sorted newest to oldest, so that `details[N]` is the latest version minus N.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"version": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The AppId version.",
+						},
+						"components": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "The numeric components of the version, used for sorting.",
+							Elem: &schema.Schema{
+								Type: schema.TypeInt,
+							},
+						},
+						"offset_from_latest": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "How many versions older this version is than the latest version (0 for the latest version).",
+						},
+						"is_latest": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "True if this is the latest version.",
+						},
+					},
+				},
+			},
 		},
 	}
 }
 
 func readAppIdVersions(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	input := appid.ListInput{
-		MaxResults: d.Get("max_results").(int),
-		NextToken:  d.Get("token").(string),
-	}
-
-	d.Set("max_results", input.MaxResults)
-	d.Set("token", input.NextToken)
+	maxResults := d.Get("max_results").(int)
+	token := d.Get("token").(string)
 
 	tflog.Info(
 		ctx, "read appid versions",
-		map[string]interface{}{"max_results": input.MaxResults,
-			"token": input.NextToken},
+		map[string]interface{}{
+			"max_results": maxResults,
+			"token":       token,
+		},
 	)
 
 	svc := meta.(*api.ApiClient)
 
-	ans, err := svc.ListAppID(ctx, input)
+	listing, err := listAppIdVersions(ctx, svc, maxResults, token)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
 	d.SetId(strings.Join(
-		[]string{strconv.Itoa(input.MaxResults), input.NextToken},
+		[]string{strconv.Itoa(maxResults), token},
 		IdSeparator,
 	))
 
-	d.Set("versions", ans.Response.Versions)
-	d.Set("next_token", ans.Response.NextToken)
+	var latest string
+	details := make([]interface{}, 0, len(listing))
+	for i := len(listing) - 1; i >= 0; i-- {
+		offset := len(listing) - 1 - i
+		if offset == 0 {
+			latest = listing[i]
+		}
+		components := make([]interface{}, 0, 2)
+		for _, x := range appIdVersionRegex.FindAllString(listing[i], -1) {
+			num, _ := strconv.Atoi(x)
+			components = append(components, num)
+		}
+		details = append(details, map[string]interface{}{
+			"version":            listing[i],
+			"components":         components,
+			"offset_from_latest": offset,
+			"is_latest":          offset == 0,
+		})
+	}
+
+	d.Set("max_results", maxResults)
+	d.Set("token", token)
+	d.Set("next_token", "")
+	d.Set("versions", listing)
+	d.Set("latest", latest)
+	d.Set("details", details)
+
 	return nil
 }
 
@@ -100,54 +162,55 @@ func dataSourceAppIdVersion() *schema.Resource {
 			"max_results": {
 				Type:        schema.TypeInt,
 				Optional:    true,
-				Description: "Max results.",
+				Description: "Max results per page. All pages are retrieved.",
 				Default:     100,
 			},
 			"token": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				Description: "Pagination token.",
+				Description: "Pagination token to start from.",
+				Deprecated:  "All pages are now retrieved automatically, so this param is no longer needed.",
 			},
 			"next_token": {
 				Type:        schema.TypeString,
 				Computed:    true,
 				Description: "Token for the next page of results.",
+				Deprecated:  "All pages are now retrieved automatically, so this attribute is always empty.",
 			},
 			"applications": {
 				Type:        schema.TypeList,
 				Computed:    true,
-				Description: "List of applications.",
+				Description: "List of applications, sorted by name.",
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
 			},
+			"application_count": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The number of applications.",
+			},
 		},
 	}
 }
 
 func readAppIdVersion(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	input := appid.ReadInput{
-		Version:    d.Get("version").(string),
-		MaxResults: d.Get("max_results").(int),
-		NextToken:  d.Get("token").(string),
-	}
-
-	d.Set("version", input.Version)
-	d.Set("max_results", input.MaxResults)
-	d.Set("token", input.NextToken)
+	version := d.Get("version").(string)
+	maxResults := d.Get("max_results").(int)
+	token := d.Get("token").(string)
 
 	tflog.Info(
 		ctx, "read appid version",
 		map[string]interface{}{
-			"version":     input.Version,
-			"max_results": input.MaxResults,
-			"token":       input.NextToken,
+			"version":     version,
+			"max_results": maxResults,
+			"token":       token,
 		},
 	)
 
 	svc := meta.(*api.ApiClient)
 
-	ans, err := svc.ReadAppID(ctx, input)
+	listing, err := listApplications(ctx, svc, version, maxResults, token)
 	if err != nil {
 		if isObjectNotFound(err) {
 			d.SetId("")
@@ -155,12 +218,336 @@ func readAppIdVersion(ctx context.Context, d *schema.ResourceData, meta interfac
 		}
 		return diag.FromErr(err)
 	}
+	sort.Strings(listing)
+
+	d.SetId(version)
+	d.Set("version", version)
+	d.Set("max_results", maxResults)
+	d.Set("token", token)
+	d.Set("next_token", "")
+	d.Set("applications", listing)
+	d.Set("application_count", len(listing))
+
+	return nil
+}
+
+// listAppIdVersions returns all AppId versions, sorted oldest to newest.
+func listAppIdVersions(ctx context.Context, svc *api.ApiClient, maxResults int, nt string) ([]string, error) {
+	var listing []string
+	for {
+		input := appid.ListInput{
+			MaxResults: maxResults,
+			NextToken:  nt,
+		}
+		ans, err := svc.ListAppID(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+
+		listing = append(listing, ans.Response.Versions...)
+		if ans.Response.NextToken == "" || ans.Response.NextToken == nt {
+			break
+		}
+		nt = ans.Response.NextToken
+	}
+
+	sort.SliceStable(listing, func(i, j int) bool {
+		return compareAppIdVersions(listing[i], listing[j]) < 0
+	})
+
+	return listing, nil
+}
+
+// listApplications returns all applications in the given AppId version.
+func listApplications(ctx context.Context, svc *api.ApiClient, version string, maxResults int, nt string) ([]string, error) {
+	var listing []string
+	for {
+		input := appid.ReadInput{
+			Version:    version,
+			MaxResults: maxResults,
+			NextToken:  nt,
+		}
+		ans, err := svc.ReadAppID(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+
+		listing = append(listing, ans.Response.Applications...)
+		if ans.Response.NextToken == "" || ans.Response.NextToken == nt {
+			break
+		}
+		nt = ans.Response.NextToken
+	}
+
+	return listing, nil
+}
+
+// compareAppIdVersions compares AppId versions (such as "8595-7473") by their
+// numeric components, returning -1, 0, or 1.
+func compareAppIdVersions(a, b string) int {
+	av := appIdVersionRegex.FindAllString(a, -1)
+	bv := appIdVersionRegex.FindAllString(b, -1)
+
+	for i := 0; i < len(av) && i < len(bv); i++ {
+		x, _ := strconv.Atoi(av[i])
+		y, _ := strconv.Atoi(bv[i])
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+
+	switch {
+	case len(av) < len(bv):
+		return -1
+	case len(av) > len(bv):
+		return 1
+	}
+
+	return strings.Compare(a, b)
+}
+
+var appIdVersionRegex = regexp.MustCompile(`\d+`)
+
+// Data source (app-id version diff).
+func dataSourceAppIdVersionDiff() *schema.Resource {
+	return &schema.Resource{
+		Description: "Data source to compare the applications in two AppId versions, such as before upgrading the `app_id_version` of a NGFW or the `minimum_app_id_version` of a rulestack.",
+
+		ReadContext: readAppIdVersionDiff,
+
+		Schema: map[string]*schema.Schema{
+			"from_version": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The current AppId version.",
+			},
+			"to_version": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The AppId version to compare against.",
+			},
+			"detect_renames": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Retrieve the details of each added application to find the applications that were renamed. This requires one API call per added application.",
+			},
+			RulestackName: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Check the candidate security rules of this rulestack for references to removed applications.",
+			},
+			ScopeName: scopeSchema(),
+			"added": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Applications in `to_version` that are not in `from_version`.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"removed": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Applications in `from_version` that are not in `to_version`.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"renamed": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "The previous application name (as the key) and the new application name (as the value). Only populated if `detect_renames` is enabled. Renamed applications are also included in `added` and `removed`.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"affected_rules": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Security rules in the rulestack that reference removed applications.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						RuleListName: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The rulebase.",
+						},
+						"priority": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The rule priority.",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The rule name.",
+						},
+						"applications": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "The removed applications referenced by the rule.",
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func readAppIdVersionDiff(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	svc := meta.(*api.ApiClient)
+	cache := getSessionCache(svc)
+
+	from := d.Get("from_version").(string)
+	to := d.Get("to_version").(string)
+	stack := d.Get(RulestackName).(string)
+	scope := d.Get(ScopeName).(string)
+
+	tflog.Info(
+		ctx, "read appid version diff",
+		map[string]interface{}{
+			"from_version": from,
+			"to_version":   to,
+			RulestackName:  stack,
+			ScopeName:      scope,
+		},
+	)
+
+	fromApps, err := cache.appIdApplications(ctx, svc, from)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	toApps, err := cache.appIdApplications(ctx, svc, to)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	added := stringSliceDifference(toApps, fromApps)
+	removed := stringSliceDifference(fromApps, toApps)
+
+	var renamed map[string]interface{}
+	if d.Get("detect_renames").(bool) && len(removed) > 0 {
+		isRemoved := make(map[string]bool, len(removed))
+		for _, x := range removed {
+			isRemoved[x] = true
+		}
+		for _, x := range added {
+			info, err := cache.appIdApplication(ctx, to, x)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+			if prev := info.PreviouslyIdentifiedAs; isRemoved[prev] {
+				if renamed == nil {
+					renamed = make(map[string]interface{})
+				}
+				renamed[prev] = x
+			}
+		}
+	}
 
-	d.SetId(input.Version)
-	d.Set("next_token", ans.Response.NextToken)
-	d.Set("applications", ans.Response.Applications)
+	var affected []interface{}
+	if stack != "" && len(removed) > 0 {
+		affected, err = rulesReferencingApplications(ctx, svc, scope, stack, removed)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	d.SetId(strings.Join([]string{from, to, scope, stack}, IdSeparator))
+	d.Set(ScopeName, scope)
+	d.Set("added", added)
+	d.Set("removed", removed)
+	d.Set("renamed", renamed)
+	if err = d.Set("affected_rules", affected); err != nil {
+		return diag.FromErr(err)
+	}
 
 	return nil
 }
 
-// Data source (app-id application).
+// rulesReferencingApplications returns the candidate security rules in the
+// given rulestack that reference any of the given applications.
+func rulesReferencingApplications(ctx context.Context, svc *api.ApiClient, scope, stack string, apps []string) ([]interface{}, error) {
+	lookup := make(map[string]bool, len(apps))
+	for _, x := range apps {
+		lookup[x] = true
+	}
+
+	// Local rulestacks only have local rules, global ones pre and post rules.
+	rlists := []string{security.LOCAL_RULE}
+	if scope == permissions.GlobalScope {
+		rlists = []string{security.PRE_RULE, security.POST_RULE}
+	}
+
+	var ans []interface{}
+	for _, rlist := range rlists {
+		var nt string
+		var listing []security.ListEntryCandidate
+		for {
+			res, err := svc.ListSecurityRule(ctx, security.ListInput{
+				Rulestack:  stack,
+				RuleList:   rlist,
+				Scope:      scope,
+				Candidate:  true,
+				MaxResults: 100,
+				NextToken:  nt,
+			})
+			if err != nil {
+				if isObjectNotFound(err) {
+					break
+				}
+				return nil, err
+			}
+			if res.Response == nil {
+				break
+			}
+			listing = append(listing, res.Response.Candidates...)
+			if res.Response.NextToken == "" || res.Response.NextToken == nt {
+				break
+			}
+			nt = res.Response.NextToken
+		}
+
+		for _, x := range listing {
+			res, err := svc.ReadSecurityRule(ctx, security.ReadInput{
+				Scope:     scope,
+				Rulestack: stack,
+				RuleList:  rlist,
+				Priority:  x.Priority,
+				Candidate: true,
+			})
+			if err != nil {
+				if isObjectNotFound(err) {
+					continue
+				}
+				return nil, err
+			}
+			if res.Response == nil || res.Response.Candidate == nil {
+				continue
+			}
+
+			var refs []string
+			for _, app := range res.Response.Candidate.Applications {
+				if lookup[app] {
+					refs = append(refs, app)
+				}
+			}
+			if len(refs) > 0 {
+				ans = append(ans, map[string]interface{}{
+					RuleListName:   rlist,
+					"priority":     x.Priority,
+					"name":         res.Response.Candidate.Name,
+					"applications": refs,
+				})
+			}
+		}
+	}
+
+	return ans, nil
+}
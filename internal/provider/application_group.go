@@ -0,0 +1,306 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api"
+	"github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api/appid"
+)
+
+// Data source (application group).
+func dataSourceApplicationGroup() *schema.Resource {
+	return &schema.Resource{
+		Description: "Data source to resolve a named group of applications and / or application filters against the AppId catalog into a list of applications, suitable for the `applications` param of `cloudngfwaws_security_rule`. Resolving filters requires retrieving the details of every application in the AppId version, which is one API call per application on every read, including each refresh. The details are cached per AppId version for the rest of the provider session, so other application groups using the same version don't repeat them.",
+
+		ReadContext: readApplicationGroup,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The application group name.",
+			},
+			"app_id_version": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The AppId version to resolve the applications against. If unspecified, the latest AppId version is used.",
+			},
+			"applications": {
+				Type:         schema.TypeSet,
+				Optional:     true,
+				Description:  "Applications that are always members of the group. These are checked against the AppId version.",
+				AtLeastOneOf: []string{"applications", "filter"},
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"filter": {
+				Type:         schema.TypeList,
+				Optional:     true,
+				Description:  "Application filter. An application matches a filter if it matches every param specified in that filter, and matches any one of the values given for each param. Each filter must specify at least one param. Applications matching any of the filters are members of the group.",
+				AtLeastOneOf: []string{"applications", "filter"},
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"categories": {
+							Type:        schema.TypeSet,
+							Optional:    true,
+							Description: "Application categories (such as `collaboration`).",
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"subcategories": {
+							Type:        schema.TypeSet,
+							Optional:    true,
+							Description: "Application subcategories (such as `file-sharing`).",
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"technologies": {
+							Type:        schema.TypeSet,
+							Optional:    true,
+							Description: "Application technologies (such as `browser-based`).",
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"risks": {
+							Type:        schema.TypeSet,
+							Optional:    true,
+							Description: addIntBetweenValidation("Application risk levels.", 1, 5),
+							Elem: &schema.Schema{
+								Type:         schema.TypeInt,
+								ValidateFunc: validation.IntBetween(1, 5),
+							},
+						},
+					},
+				},
+			},
+			"exclude": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Applications to remove from the group.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"max_concurrency": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Description:  addIntBetweenValidation("The maximum number of applications to read at the same time when resolving filters.", 1, 20),
+				Default:      5,
+				ValidateFunc: validation.IntBetween(1, 20),
+			},
+			"members": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The sorted list of applications in the group.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+func readApplicationGroup(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	svc := meta.(*api.ApiClient)
+	cache := getSessionCache(svc)
+
+	name := d.Get("name").(string)
+	version := d.Get("app_id_version").(string)
+	if version == "" {
+		listing, err := cache.appIdVersions(ctx, svc)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if len(listing) == 0 {
+			return diag.Errorf("no AppId versions found")
+		}
+		version = listing[len(listing)-1]
+	}
+
+	filters, err := loadApplicationFilters(d.Get("filter").([]interface{}))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	tflog.Info(
+		ctx, "read application group",
+		map[string]interface{}{
+			"name":           name,
+			"app_id_version": version,
+			"filters":        len(filters),
+		},
+	)
+
+	catalog, err := cache.appIdApplications(ctx, svc, version)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	known := make(map[string]bool, len(catalog))
+	for _, x := range catalog {
+		known[x] = true
+	}
+
+	members := make(map[string]bool)
+	for _, x := range setToSlice(d.Get("applications")) {
+		if !known[x] {
+			if guess := closestMatch(x, catalog); guess != "" {
+				return diag.Errorf("unknown application %q in AppId version %s, did you mean %q?", x, version, guess)
+			}
+			return diag.Errorf("unknown application %q in AppId version %s", x, version)
+		}
+		members[x] = true
+	}
+
+	if len(filters) > 0 {
+		names := make([]string, 0, len(catalog))
+		for _, x := range catalog {
+			if !members[x] {
+				names = append(names, x)
+			}
+		}
+		details, err := describeApplications(ctx, cache, version, names, d.Get("max_concurrency").(int))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		for i, x := range names {
+			for _, f := range filters {
+				if f.matches(details[i]) {
+					members[x] = true
+					break
+				}
+			}
+		}
+	}
+
+	for _, x := range setToSlice(d.Get("exclude")) {
+		delete(members, x)
+	}
+
+	list := make([]string, 0, len(members))
+	for x := range members {
+		list = append(list, x)
+	}
+	sort.Strings(list)
+
+	d.SetId(name)
+	d.Set("app_id_version", version)
+	if err = d.Set("members", list); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting members: %s", err))
+	}
+
+	return nil
+}
+
+// describeApplications returns the details of the given applications, reading
+// up to concurrency of them at the same time.
+func describeApplications(ctx context.Context, cache *sessionCache, version string, names []string, concurrency int) ([]appid.ApplicationDetails, error) {
+	ans := make([]appid.ApplicationDetails, len(names))
+	errs := make([]error, len(names))
+	sem := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			ans[i], errs[i] = cache.appIdApplication(ctx, version, name)
+		}(i, name)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ans, nil
+}
+
+type applicationFilter struct {
+	categories    []string
+	subcategories []string
+	technologies  []string
+	risks         []int
+}
+
+// loadApplicationFilters returns the given filter params.  Empty filters are
+// an error, as they would match every application in the catalog.
+func loadApplicationFilters(list []interface{}) ([]applicationFilter, error) {
+	ans := make([]applicationFilter, 0, len(list))
+	for i, x := range list {
+		var f applicationFilter
+		if m, ok := x.(map[string]interface{}); ok {
+			f.categories = setToSlice(m["categories"])
+			f.subcategories = setToSlice(m["subcategories"])
+			f.technologies = setToSlice(m["technologies"])
+			if risks, ok := m["risks"].(*schema.Set); ok {
+				for _, r := range risks.List() {
+					f.risks = append(f.risks, r.(int))
+				}
+			}
+		}
+		if len(f.categories) == 0 && len(f.subcategories) == 0 && len(f.technologies) == 0 && len(f.risks) == 0 {
+			return nil, fmt.Errorf("filter %d: at least one of categories, subcategories, technologies or risks must be specified", i)
+		}
+		ans = append(ans, f)
+	}
+
+	return ans, nil
+}
+
+func (f applicationFilter) matches(info appid.ApplicationDetails) bool {
+	if !matchesAnyFold(info.Properties.Category, f.categories) {
+		return false
+	}
+	if !matchesAnyFold(info.Properties.Subcategory, f.subcategories) {
+		return false
+	}
+	if !matchesAnyFold(info.Properties.Technology, f.technologies) {
+		return false
+	}
+
+	if len(f.risks) > 0 {
+		for _, r := range f.risks {
+			if r == info.Properties.Risk {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+// matchesAnyFold returns true if values is empty or if v is one of the values,
+// ignoring case.
+func matchesAnyFold(v string, values []string) bool {
+	if len(values) == 0 {
+		return true
+	}
+
+	for _, x := range values {
+		if strings.EqualFold(v, x) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api/appid"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestLoadApplicationFilters(t *testing.T) {
+	list := []interface{}{
+		map[string]interface{}{
+			"categories":    sliceToSet([]string{"collaboration"}),
+			"subcategories": sliceToSet(nil),
+			"technologies":  sliceToSet(nil),
+			"risks":         schema.NewSet(schema.HashInt, []interface{}{4, 5}),
+		},
+	}
+
+	ans, err := loadApplicationFilters(list)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(ans) != 1 || !reflect.DeepEqual(ans[0].categories, []string{"collaboration"}) || len(ans[0].risks) != 2 {
+		t.Errorf("unexpected filters: %#v", ans)
+	}
+
+	empty := map[string]interface{}{
+		"categories":    sliceToSet(nil),
+		"subcategories": sliceToSet(nil),
+		"technologies":  sliceToSet(nil),
+		"risks":         schema.NewSet(schema.HashInt, nil),
+	}
+	for _, x := range []interface{}{empty, nil} {
+		if _, err := loadApplicationFilters(append(list, x)); err == nil {
+			t.Errorf("expected an error for the empty filter %#v", x)
+		}
+	}
+}
+
+func TestApplicationFilterMatches(t *testing.T) {
+	app := appid.ApplicationDetails{
+		Properties: appid.ApplicationProperties{
+			Category:    "collaboration",
+			Subcategory: "file-sharing",
+			Technology:  "browser-based",
+			Risk:        4,
+		},
+	}
+
+	table := []struct {
+		name   string
+		filter applicationFilter
+		want   bool
+	}{
+		{"category", applicationFilter{categories: []string{"Collaboration"}}, true},
+		{"other category", applicationFilter{categories: []string{"networking"}}, false},
+		{"any of the categories", applicationFilter{categories: []string{"networking", "collaboration"}}, true},
+		{"all params", applicationFilter{categories: []string{"collaboration"}, subcategories: []string{"file-sharing"}, technologies: []string{"browser-based"}, risks: []int{4}}, true},
+		{"one param differs", applicationFilter{categories: []string{"collaboration"}, technologies: []string{"client-server"}}, false},
+		{"risk", applicationFilter{risks: []int{4, 5}}, true},
+		{"other risk", applicationFilter{risks: []int{1, 2}}, false},
+	}
+
+	for _, tc := range table {
+		if got := tc.filter.matches(app); got != tc.want {
+			t.Errorf("%s: expected %t, got %t", tc.name, tc.want, got)
+		}
+	}
+}
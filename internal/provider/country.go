@@ -2,6 +2,8 @@ package provider
 
 import (
 	"context"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -11,6 +13,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 // Data source (list countries).
@@ -24,18 +27,34 @@ func dataSourceCountry() *schema.Resource {
 			"max_results": {
 				Type:        schema.TypeInt,
 				Optional:    true,
-				Description: "Max number of results.",
+				Description: "Max number of results per page. All pages are retrieved.",
 				Default:     100,
 			},
 			"token": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				Description: "Pagination token.",
+				Description: "Pagination token to start from.",
+				Deprecated:  "All pages are now retrieved automatically, so this param is no longer needed.",
+			},
+			"filter_codes": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Only return countries with these country codes.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"name_regex": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Only return countries whose description matches this regular expression.",
+				ValidateFunc: validation.StringIsValidRegExp,
 			},
 			"next_token": {
 				Type:        schema.TypeString,
 				Computed:    true,
 				Description: "Token for the next page of results.",
+				Deprecated:  "All pages are now retrieved automatically, so this attribute is always empty.",
 			},
 			"codes": {
 				Type:        schema.TypeMap,
@@ -45,49 +64,113 @@ func dataSourceCountry() *schema.Resource {
 					Type: schema.TypeString,
 				},
 			},
+			"countries": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "List of countries, sorted by country code.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"code": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The country code.",
+						},
+						"description": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The description.",
+						},
+					},
+				},
+			},
 		},
 	}
 }
 
 func readCountry(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	input := country.ListInput{
-		MaxResults: d.Get("max_results").(int),
-		NextToken:  d.Get("token").(string),
-	}
-
-	d.Set("max_results", input.MaxResults)
-	d.Set("token", input.NextToken)
+	maxResults := d.Get("max_results").(int)
+	token := d.Get("token").(string)
+	filterCodes := setToSlice(d.Get("filter_codes"))
+	nameRegex := d.Get("name_regex").(string)
 
 	tflog.Info(
 		ctx, "read countries",
 		map[string]interface{}{
-			"max_results": input.MaxResults,
-			"token":       input.NextToken,
+			"max_results": maxResults,
+			"token":       token,
+			"name_regex":  nameRegex,
 		},
 	)
 
 	svc := meta.(*api.ApiClient)
 
-	ans, err := svc.ListCountry(ctx, input)
+	listing, err := listCountries(ctx, svc, maxResults, token)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
+	var re *regexp.Regexp
+	if nameRegex != "" {
+		re = regexp.MustCompile(nameRegex)
+	}
+
 	d.SetId(strings.Join(
-		[]string{strconv.Itoa(input.MaxResults), input.NextToken},
+		[]string{strconv.Itoa(maxResults), token},
 		IdSeparator,
 	))
 
+	sort.Slice(listing, func(i, j int) bool {
+		return listing[i].Code < listing[j].Code
+	})
+
 	var codes map[string]interface{}
-	if ans.Response != nil && len(ans.Response.Countries) > 0 {
-		codes = make(map[string]interface{})
-		for _, x := range ans.Response.Countries {
-			codes[x.Code] = x.Description
+	countries := make([]interface{}, 0, len(listing))
+	for _, x := range listing {
+		if len(filterCodes) > 0 && !matchesAnyFold(x.Code, filterCodes) {
+			continue
+		}
+		if re != nil && !re.MatchString(x.Description) {
+			continue
 		}
+		if codes == nil {
+			codes = make(map[string]interface{})
+		}
+		codes[x.Code] = x.Description
+		countries = append(countries, map[string]interface{}{
+			"code":        x.Code,
+			"description": x.Description,
+		})
 	}
 
-	d.Set("next_token", ans.Response.NextToken)
+	d.Set("max_results", maxResults)
+	d.Set("token", token)
+	d.Set("next_token", "")
 	d.Set("codes", codes)
+	d.Set("countries", countries)
 
 	return nil
 }
+
+// listCountries returns all countries starting from the given pagination token.
+func listCountries(ctx context.Context, svc *api.ApiClient, maxResults int, nt string) ([]country.Country, error) {
+	var listing []country.Country
+	for {
+		input := country.ListInput{
+			MaxResults: maxResults,
+			NextToken:  nt,
+		}
+		ans, err := svc.ListCountry(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+
+		if ans.Response == nil {
+			return listing, nil
+		}
+		listing = append(listing, ans.Response.Countries...)
+		if ans.Response.NextToken == "" || ans.Response.NextToken == nt {
+			return listing, nil
+		}
+		nt = ans.Response.NextToken
+	}
+}
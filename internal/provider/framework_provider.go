@@ -0,0 +1,227 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	fwdiag "github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	fwprovider "github.com/hashicorp/terraform-plugin-framework/provider"
+	pschema "github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-mux/tf5muxserver"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/ngfw/aws"
+)
+
+// NewMuxServer returns a provider server that combines the SDK provider with
+// the framework provider, which hosts the features the SDK cannot serve
+// (such as provider-defined functions).
+func NewMuxServer(ctx context.Context, version string) (func() tfprotov5.ProviderServer, error) {
+	providers := []func() tfprotov5.ProviderServer{
+		New(version)().GRPCProvider,
+		providerserver.NewProtocol5(NewFramework(version)()),
+	}
+
+	muxServer, err := tf5muxserver.NewMuxServer(ctx, providers...)
+	if err != nil {
+		return nil, err
+	}
+
+	return muxServer.ProviderServer, nil
+}
+
+func NewFramework(version string) func() fwprovider.Provider {
+	return func() fwprovider.Provider {
+		return &frameworkProvider{
+			version: version,
+		}
+	}
+}
+
+type frameworkProvider struct {
+	version string
+}
+
+var (
+	_ fwprovider.ProviderWithFunctions          = &frameworkProvider{}
+	_ fwprovider.ProviderWithEphemeralResources = &frameworkProvider{}
+)
+
+func (p *frameworkProvider) Metadata(ctx context.Context, req fwprovider.MetadataRequest, resp *fwprovider.MetadataResponse) {
+	resp.TypeName = "cloudngfwaws"
+	resp.Version = p.version
+}
+
+func (p *frameworkProvider) Schema(ctx context.Context, req fwprovider.SchemaRequest, resp *fwprovider.SchemaResponse) {
+	resp.Schema = frameworkProviderSchema()
+}
+
+func (p *frameworkProvider) Configure(ctx context.Context, req fwprovider.ConfigureRequest, resp *fwprovider.ConfigureResponse) {
+	// The SDK provider already sets up a client for everything else, so only
+	// set one up here once an ephemeral resource actually needs it.
+	resp.EphemeralResourceData = &lazyAwsClient{
+		config: req.Config,
+		agent: fmt.Sprintf(
+			"Terraform/%s (+https://www.terraform.io) terraform-provider-cloudngfwaws/%s",
+			req.TerraformVersion, p.version,
+		),
+	}
+}
+
+func (p *frameworkProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
+	return nil
+}
+
+func (p *frameworkProvider) Resources(ctx context.Context) []func() resource.Resource {
+	return nil
+}
+
+func (p *frameworkProvider) EphemeralResources(ctx context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		newApiTokenEphemeralResource,
+	}
+}
+
+func (p *frameworkProvider) Functions(ctx context.Context) []func() function.Function {
+	return []func() function.Function{
+		newBuildSecurityRuleIdFunction,
+		newParseSecurityRuleIdFunction,
+		newNormalizeCidrsFunction,
+		newValidateFqdnFunction,
+	}
+}
+
+// frameworkProviderSchema mirrors providerSchema(), as the muxed providers
+// are required to present identical provider schemas to Terraform.
+func frameworkProviderSchema() pschema.Schema {
+	attrs := make(map[string]pschema.Attribute)
+
+	for name, s := range providerSchema() {
+		desc := schema.SchemaDescriptionBuilder(s)
+
+		switch s.Type {
+		case schema.TypeBool:
+			attrs[name] = pschema.BoolAttribute{
+				Optional:            s.Optional,
+				Required:            s.Required,
+				Sensitive:           s.Sensitive,
+				MarkdownDescription: desc,
+			}
+		case schema.TypeInt:
+			attrs[name] = pschema.Int64Attribute{
+				Optional:            s.Optional,
+				Required:            s.Required,
+				Sensitive:           s.Sensitive,
+				MarkdownDescription: desc,
+			}
+		case schema.TypeMap:
+			attrs[name] = pschema.MapAttribute{
+				ElementType:         types.StringType,
+				Optional:            s.Optional,
+				Required:            s.Required,
+				Sensitive:           s.Sensitive,
+				MarkdownDescription: desc,
+			}
+		case schema.TypeList:
+			attrs[name] = pschema.ListAttribute{
+				ElementType:         types.StringType,
+				Optional:            s.Optional,
+				Required:            s.Required,
+				Sensitive:           s.Sensitive,
+				MarkdownDescription: desc,
+			}
+		default:
+			attrs[name] = pschema.StringAttribute{
+				Optional:            s.Optional,
+				Required:            s.Required,
+				Sensitive:           s.Sensitive,
+				MarkdownDescription: desc,
+			}
+		}
+	}
+
+	return pschema.Schema{
+		Attributes: attrs,
+	}
+}
+
+// lazyAwsClient sets up the framework provider's client on first use.
+type lazyAwsClient struct {
+	once   sync.Once
+	config tfsdk.Config
+	agent  string
+	con    *aws.Client
+	diags  fwdiag.Diagnostics
+	err    error
+}
+
+// get returns the client, setting it up if this is the first call.
+func (c *lazyAwsClient) get(ctx context.Context) (*aws.Client, fwdiag.Diagnostics, error) {
+	c.once.Do(func() {
+		config := &frameworkConfig{
+			ctx:    ctx,
+			config: c.config,
+			schema: providerSchema(),
+		}
+		c.con, c.err = newAwsClient(config, c.agent)
+		c.diags = config.diags
+	})
+
+	return c.con, c.diags, c.err
+}
+
+// frameworkConfig adapts the framework provider's configuration to
+// providerConfig, returning the same types that the SDK's ResourceData would.
+type frameworkConfig struct {
+	ctx    context.Context
+	config tfsdk.Config
+	schema map[string]*schema.Schema
+	diags  fwdiag.Diagnostics
+}
+
+func (c *frameworkConfig) Get(key string) interface{} {
+	s, ok := c.schema[key]
+	if !ok {
+		return nil
+	}
+
+	switch s.Type {
+	case schema.TypeBool:
+		var v types.Bool
+		c.diags.Append(c.config.GetAttribute(c.ctx, path.Root(key), &v)...)
+		return v.ValueBool()
+	case schema.TypeInt:
+		var v types.Int64
+		c.diags.Append(c.config.GetAttribute(c.ctx, path.Root(key), &v)...)
+		return int(v.ValueInt64())
+	case schema.TypeMap:
+		var v map[string]string
+		c.diags.Append(c.config.GetAttribute(c.ctx, path.Root(key), &v)...)
+		ans := make(map[string]interface{}, len(v))
+		for k, x := range v {
+			ans[k] = x
+		}
+		return ans
+	case schema.TypeList:
+		var v []string
+		c.diags.Append(c.config.GetAttribute(c.ctx, path.Root(key), &v)...)
+		ans := make([]interface{}, 0, len(v))
+		for _, x := range v {
+			ans = append(ans, x)
+		}
+		return ans
+	default:
+		var v types.String
+		c.diags.Append(c.config.GetAttribute(c.ctx, path.Root(key), &v)...)
+		return v.ValueString()
+	}
+}
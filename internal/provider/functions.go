@@ -0,0 +1,201 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Function: build_security_rule_id.
+type buildSecurityRuleIdFunction struct{}
+
+func newBuildSecurityRuleIdFunction() function.Function {
+	return &buildSecurityRuleIdFunction{}
+}
+
+func (f *buildSecurityRuleIdFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "build_security_rule_id"
+}
+
+func (f *buildSecurityRuleIdFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Build a security rule ID.",
+		MarkdownDescription: "Returns the ID of a `cloudngfwaws_security_rule` resource, suitable for use with `terraform import`.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                ScopeName,
+				MarkdownDescription: "The rulestack's scope (`Local` or `Global`).",
+			},
+			function.StringParameter{
+				Name:                RulestackName,
+				MarkdownDescription: "The rulestack.",
+			},
+			function.StringParameter{
+				Name:                RuleListName,
+				MarkdownDescription: "The rulebase (`PreRule`, `PostRule`, or `LocalRule`).",
+			},
+			function.Int64Parameter{
+				Name:                "priority",
+				MarkdownDescription: "The rule priority.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *buildSecurityRuleIdFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var scope, stack, rlist string
+	var priority int64
+
+	resp.Error = req.Arguments.Get(ctx, &scope, &stack, &rlist, &priority)
+	if resp.Error != nil {
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, buildSecurityRuleId(scope, stack, rlist, int(priority)))
+}
+
+// Function: parse_security_rule_id.
+type parseSecurityRuleIdFunction struct{}
+
+type securityRuleIdModel struct {
+	Scope     string `tfsdk:"scope"`
+	Rulestack string `tfsdk:"rulestack"`
+	RuleList  string `tfsdk:"rule_list"`
+	Priority  int64  `tfsdk:"priority"`
+}
+
+func newParseSecurityRuleIdFunction() function.Function {
+	return &parseSecurityRuleIdFunction{}
+}
+
+func (f *parseSecurityRuleIdFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "parse_security_rule_id"
+}
+
+func (f *parseSecurityRuleIdFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Parse a security rule ID.",
+		MarkdownDescription: "Splits the ID of a `cloudngfwaws_security_rule` resource into an object with `scope`, `rulestack`, `rule_list`, and `priority` attributes.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "id",
+				MarkdownDescription: "The security rule ID.",
+			},
+		},
+		Return: function.ObjectReturn{
+			AttributeTypes: map[string]attr.Type{
+				ScopeName:     types.StringType,
+				RulestackName: types.StringType,
+				RuleListName:  types.StringType,
+				"priority":    types.Int64Type,
+			},
+		},
+	}
+}
+
+func (f *parseSecurityRuleIdFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var id string
+
+	resp.Error = req.Arguments.Get(ctx, &id)
+	if resp.Error != nil {
+		return
+	}
+
+	scope, stack, rlist, priority, err := parseSecurityRuleId(id)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Error in parsing ID %q: %s", id, err))
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, securityRuleIdModel{
+		Scope:     scope,
+		Rulestack: stack,
+		RuleList:  rlist,
+		Priority:  int64(priority),
+	})
+}
+
+// Function: normalize_cidrs.
+type normalizeCidrsFunction struct{}
+
+func newNormalizeCidrsFunction() function.Function {
+	return &normalizeCidrsFunction{}
+}
+
+func (f *normalizeCidrsFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "normalize_cidrs"
+}
+
+func (f *normalizeCidrsFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Normalize a list of CIDRs.",
+		MarkdownDescription: "Returns the given CIDRs and IP addresses with host bits masked off, duplicates removed, and sorted by address, suitable for the `prefix_list` param of `cloudngfwaws_prefix_list`.",
+		Parameters: []function.Parameter{
+			function.ListParameter{
+				Name:                "cidrs",
+				ElementType:         types.StringType,
+				MarkdownDescription: "List of CIDRs or IP addresses.",
+			},
+		},
+		Return: function.ListReturn{
+			ElementType: types.StringType,
+		},
+	}
+}
+
+func (f *normalizeCidrsFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var cidrs []string
+
+	resp.Error = req.Arguments.Get(ctx, &cidrs)
+	if resp.Error != nil {
+		return
+	}
+
+	ans, err := normalizeCidrs(cidrs)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, ans)
+}
+
+// Function: validate_fqdn.
+type validateFqdnFunction struct{}
+
+func newValidateFqdnFunction() function.Function {
+	return &validateFqdnFunction{}
+}
+
+func (f *validateFqdnFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "validate_fqdn"
+}
+
+func (f *validateFqdnFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Check if a value is a valid FQDN.",
+		MarkdownDescription: "Returns `true` if the given value is a valid FQDN for a `cloudngfwaws_fqdn_list`. A wildcard is only allowed as the whole leftmost label (such as `*.example.com`), and labels may start with an underscore (such as `_dmarc.example.com`).",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "fqdn",
+				MarkdownDescription: "The FQDN to check.",
+			},
+		},
+		Return: function.BoolReturn{},
+	}
+}
+
+func (f *validateFqdnFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var fqdn string
+
+	resp.Error = req.Arguments.Get(ctx, &fqdn)
+	if resp.Error != nil {
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, validateFqdn(fqdn) == nil)
+}
@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccFunctionSecurityRuleId(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV5ProviderFactories: protoV5ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+output "id" {
+    value = provider::cloudngfwaws::build_security_rule_id("Local", "my-rulestack", "PreRule", 10)
+}
+
+output "rulestack" {
+    value = provider::cloudngfwaws::parse_security_rule_id("Local:my-rulestack:PreRule:10").rulestack
+}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckOutput("id", "Local:my-rulestack:PreRule:10"),
+					resource.TestCheckOutput("rulestack", "my-rulestack"),
+				),
+			},
+			{
+				Config: `
+output "id" {
+    value = provider::cloudngfwaws::parse_security_rule_id("my-rulestack:PreRule:10")
+}`,
+				ExpectError: regexp.MustCompile("Error in parsing ID"),
+			},
+		},
+	})
+}
+
+func TestAccFunctionNormalizeCidrs(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV5ProviderFactories: protoV5ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+output "cidrs" {
+    value = join(",", provider::cloudngfwaws::normalize_cidrs(["192.168.1.7/24", "10.0.0.0/8", "192.168.1.0/24", "10.2.3.4"]))
+}`,
+				Check: resource.TestCheckOutput("cidrs", "10.0.0.0/8,10.2.3.4,192.168.1.0/24"),
+			},
+			{
+				Config: `
+output "cidrs" {
+    value = provider::cloudngfwaws::normalize_cidrs(["10.0.0.0/33"])
+}`,
+				ExpectError: regexp.MustCompile("invalid CIDR"),
+			},
+		},
+	})
+}
+
+func TestAccFunctionValidateFqdn(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV5ProviderFactories: protoV5ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+output "wildcard" {
+    value = provider::cloudngfwaws::validate_fqdn("*.example.com")
+}
+
+output "bad_wildcard" {
+    value = provider::cloudngfwaws::validate_fqdn("www.*.example.com")
+}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckOutput("wildcard", "true"),
+					resource.TestCheckOutput("bad_wildcard", "false"),
+				),
+			},
+		},
+	})
+}
@@ -4,13 +4,16 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"strconv"
+	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api"
 	"github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api/firewall"
 	ngfw "github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api/firewall"
 	"github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api/tag"
+	"github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/ngfw/aws"
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -20,8 +23,10 @@ import (
 
 // Data source (list NGFWs).
 func dataSourceNgfws() *schema.Resource {
+	endpoint_mode_opts := []string{"ServiceManaged", "CustomerManaged"}
+
 	return &schema.Resource{
-		Description: "Data source get a list of NGFWs.",
+		Description: "Data source get a list of NGFWs. Filtering on anything other than `rulestack`, `region` or `name_prefix` requires reading each NGFW, as does `describe`.",
 
 		ReadContext: readNgfws,
 
@@ -34,7 +39,7 @@ func dataSourceNgfws() *schema.Resource {
 			"vpc_ids": {
 				Type:        schema.TypeList,
 				Optional:    true,
-				Description: "List of vpc ids to filter on.",
+				Description: "List of vpc ids to filter on. A NGFW matches if it (or one of its endpoints) is in any of the VPCs.",
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
@@ -44,6 +49,45 @@ func dataSourceNgfws() *schema.Resource {
 				Optional:    true,
 				Description: "The region to filter on.",
 			},
+			"name_prefix": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The NGFW name prefix to filter on.",
+			},
+			"tags": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Description: "Tags to filter on. A NGFW matches if it has all of the given tags.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"firewall_statuses": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Firewall statuses (such as `CREATE_COMPLETE`) to filter on.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"endpoint_mode": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  addStringInSliceValidation("The endpoint mode to filter on.", endpoint_mode_opts),
+				ValidateFunc: validation.StringInSlice(endpoint_mode_opts, false),
+			},
+			"describe": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Read the details of each NGFW.",
+			},
+			"max_concurrency": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Description:  addIntBetweenValidation("The maximum number of NGFWs to read at the same time.", 1, 20),
+				Default:      5,
+				ValidateFunc: validation.IntBetween(1, 20),
+			},
 			"instances": {
 				Type:        schema.TypeList,
 				Computed:    true,
@@ -60,6 +104,88 @@ func dataSourceNgfws() *schema.Resource {
 							Computed:    true,
 							Description: "The NGFW ID.",
 						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The NGFW name.",
+						},
+						"account_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The account ID.",
+						},
+						"vpc_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The VPC ID (if details were read).",
+						},
+						"endpoint_mode": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The endpoint mode (if details were read).",
+						},
+						RulestackName: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The rulestack (if details were read).",
+						},
+						"global_rulestack": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The global rulestack (if details were read).",
+						},
+						"firewall_status": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The firewall status (if details were read).",
+						},
+						"rulestack_status": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The rulestack status (if details were read).",
+						},
+						"tags": {
+							Type:        schema.TypeMap,
+							Computed:    true,
+							Description: "The tags (if details were read).",
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"endpoints": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "The endpoints (if details were read).",
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"endpoint_id": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "The endpoint ID.",
+									},
+									"subnet_id": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "The subnet ID.",
+									},
+									"vpc_id": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "The VPC ID.",
+									},
+									"account_id": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "The account ID.",
+									},
+									"status": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "The attachment status.",
+									},
+								},
+							},
+						},
 					},
 				},
 			},
@@ -75,14 +201,243 @@ func readNgfws(ctx context.Context, d *schema.ResourceData, meta interface{}) di
 	if region == "" {
 		region = svc.GetRegion(ctx)
 	}
+	namePrefix := d.Get("name_prefix").(string)
+	vpcIds := toStringSlice(d.Get("vpc_ids"))
+	tags := loadTags(d.Get("tags"))
+	statuses := setToSlice(d.Get("firewall_statuses"))
+	endpointMode := d.Get("endpoint_mode").(string)
+	describe := d.Get("describe").(bool) || len(vpcIds) > 0 || len(tags) > 0 || len(statuses) > 0 || endpointMode != ""
+
 	tflog.Info(
 		ctx, "read ngfws",
 		map[string]interface{}{
 			"ds":          true,
 			RulestackName: stack,
+			"describe":    describe,
 		},
 	)
 
+	listing, err := listNgfws(ctx, svc, stack, region)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if namePrefix != "" {
+		filtered := make([]ngfw.ListFirewall, 0, len(listing))
+		for _, x := range listing {
+			if strings.HasPrefix(x.Name, namePrefix) {
+				filtered = append(filtered, x)
+			}
+		}
+		listing = filtered
+	}
+
+	var details []*ngfw.ReadResponse
+	if describe {
+		ids := make([]string, 0, len(listing))
+		for _, x := range listing {
+			ids = append(ids, x.FirewallId)
+		}
+		details, err = describeNgfws(ctx, svc, ids, d.Get("max_concurrency").(int))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	instances := make([]interface{}, 0, len(listing))
+	for i, x := range listing {
+		inst := map[string]interface{}{
+			"firewall_id": x.FirewallId,
+			"region":      x.Region,
+			"name":        x.Name,
+			"account_id":  x.AccountId,
+		}
+
+		if describe {
+			o := details[i]
+			if o == nil || !ngfwsFilter(*o, vpcIds, tags, statuses, endpointMode) {
+				continue
+			}
+
+			eps := make([]interface{}, 0, len(o.Firewall.Endpoints))
+			for _, ep := range o.Firewall.Endpoints {
+				eps = append(eps, map[string]interface{}{
+					"endpoint_id": ep.EndpointId,
+					"subnet_id":   ep.SubnetId,
+					"vpc_id":      ep.VpcId,
+					"account_id":  ep.AccountId,
+					"status":      ep.Status,
+				})
+			}
+
+			inst["vpc_id"] = o.Firewall.VpcId
+			inst["endpoint_mode"] = o.Firewall.EndpointMode
+			inst[RulestackName] = o.Firewall.Rulestack
+			inst["global_rulestack"] = o.Firewall.GlobalRulestack
+			inst["firewall_status"] = o.Status.FirewallStatus
+			inst["rulestack_status"] = o.Status.RulestackStatus
+			inst["tags"] = dumpTags(o.Firewall.Tags)
+			inst["endpoints"] = eps
+		}
+
+		instances = append(instances, inst)
+	}
+
+	d.SetId(strings.Join([]string{region, stack}, IdSeparator))
+
+	d.Set(RulestackName, stack)
+	if err = d.Set("instances", instances); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting instances: %s", err))
+	}
+
+	return nil
+}
+
+// ngfwsFilter returns true if the NGFW matches all of the given filters, any of
+// which may be empty.
+func ngfwsFilter(o ngfw.ReadResponse, vpcIds []string, tags []tag.Details, statuses []string, endpointMode string) bool {
+	if endpointMode != "" && o.Firewall.EndpointMode != endpointMode {
+		return false
+	}
+
+	if !matchesAnyFold(o.Status.FirewallStatus, statuses) {
+		return false
+	}
+
+	if !ngfwMatches(o.Firewall, "", tags) {
+		return false
+	}
+
+	if len(vpcIds) == 0 {
+		return true
+	}
+	for _, x := range vpcIds {
+		if ngfwMatches(o.Firewall, x, nil) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// describeNgfws reads the given NGFWs, at most concurrency at a time.  The
+// answer is in the same order as ids, with nil for any NGFW that is gone.
+func describeNgfws(ctx context.Context, svc *api.ApiClient, ids []string, concurrency int) ([]*ngfw.ReadResponse, error) {
+	ans := make([]*ngfw.ReadResponse, len(ids))
+	errs := make([]error, len(ids))
+	sem := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			res, err := svc.ReadFirewall(ctx, ngfw.ReadInput{FirewallId: id})
+			if err != nil {
+				if !isObjectNotFound(err) {
+					errs[i] = err
+				}
+				return
+			}
+			ans[i] = &res.Response
+		}(i, id)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ans, nil
+}
+
+var secretArnRegex = regexp.MustCompile(`^arn:aws[\w-]*:secretsmanager:[a-z0-9-]+:\d{12}:secret:[\w/+=.@-]+$`)
+
+// validateUserIdConfig checks the parts of the user_id config that can't be
+// checked per attribute: the port of an enabled config, and that the names of
+// the custom include/exclude networks are unique.
+func validateUserIdConfig(diff *schema.ResourceDiff) error {
+	list := diff.Get("user_id").([]interface{})
+	if len(list) == 0 || list[0] == nil {
+		return nil
+	}
+	cfg := list[0].(map[string]interface{})
+
+	if cfg["enabled"].(bool) && diff.NewValueKnown("user_id.0.port") {
+		if port := cfg["port"].(int); port < 1 || port > 65535 {
+			return fmt.Errorf("user_id port must be between 1 and 65535, not %d", port)
+		}
+	}
+
+	names := make(map[string]int)
+	for i, x := range cfg["custom_include_exclude_network"].([]interface{}) {
+		if x == nil || !diff.NewValueKnown(fmt.Sprintf("user_id.0.custom_include_exclude_network.%d.name", i)) {
+			continue
+		}
+		name := x.(map[string]interface{})["name"].(string)
+		if j, ok := names[name]; ok {
+			return fmt.Errorf("user_id custom_include_exclude_network %d: name %q is already used by custom_include_exclude_network %d", i, name, j)
+		}
+		names[name] = i
+	}
+
+	return nil
+}
+
+// validateEgressNat checks that BYOIP egress NAT has an IPAM pool, and that no
+// endpoint has egress NAT enabled unless the NGFW does.
+func validateEgressNat(diff *schema.ResourceDiff) error {
+	if !diff.NewValueKnown("egress_nat") {
+		return nil
+	}
+
+	enabled := false
+	if list := diff.Get("egress_nat").([]interface{}); len(list) > 0 && list[0] != nil {
+		cfg := list[0].(map[string]interface{})
+		if !diff.NewValueKnown("egress_nat.0.enabled") {
+			return nil
+		}
+		enabled = cfg["enabled"].(bool)
+
+		if settings := cfg["settings"].([]interface{}); len(settings) > 0 && settings[0] != nil {
+			sm := settings[0].(map[string]interface{})
+			if sm["ip_pool_type"].(string) == "BYOIP" && sm["ipam_pool_id"].(string) == "" && diff.NewValueKnown("egress_nat.0.settings.0.ipam_pool_id") {
+				return fmt.Errorf("ipam_pool_id is required when ip_pool_type is BYOIP")
+			}
+		}
+	}
+
+	if enabled {
+		return nil
+	}
+
+	// Only the config matters here, as egress_nat_enabled is computed and
+	// cleared on the endpoints when egress NAT is disabled on the NGFW.
+	eps := diff.GetRawConfig().GetAttr("endpoints")
+	if !eps.IsKnown() || eps.IsNull() {
+		return nil
+	}
+	for i, x := range eps.AsValueSlice() {
+		if !x.IsKnown() || x.IsNull() {
+			continue
+		}
+		v := x.GetAttr("egress_nat_enabled")
+		if v.IsKnown() && !v.IsNull() && v.True() {
+			return fmt.Errorf("endpoints %d: egress_nat_enabled requires egress NAT to be enabled on the NGFW", i)
+		}
+	}
+
+	return nil
+}
+
+// listNgfws returns all NGFWs in the given region, optionally only those using
+// the given rulestack.
+func listNgfws(ctx context.Context, svc *api.ApiClient, stack, region string) ([]ngfw.ListFirewall, error) {
 	var nt string
 	var listing []ngfw.ListFirewall
 	for {
@@ -97,58 +452,132 @@ func readNgfws(ctx context.Context, d *schema.ResourceData, meta interface{}) di
 			if isObjectNotFound(err) {
 				break
 			}
-			return diag.FromErr(err)
+			return nil, err
 		}
 
 		listing = append(listing, ans.Response.Firewalls...)
-		nt = ans.Response.NextToken
-		if nt == "" {
+		// Guard against the same page being returned repeatedly.
+		if ans.Response.NextToken == "" || ans.Response.NextToken == nt {
 			break
 		}
+		nt = ans.Response.NextToken
 	}
 
-	d.SetId(strings.Join(
-		append([]string{strconv.Itoa(len(listing))}, region),
-		IdSeparator,
-	))
+	return listing, nil
+}
 
-	instances := make([]interface{}, 0, len(listing))
+// findNgfwConcurrency is how many NGFWs findNgfw reads at the same time when
+// filtering by VPC or tags.
+const findNgfwConcurrency = 5
+
+// findNgfw returns the ID of the only NGFW matching the given name, VPC and
+// tags, any of which may be empty.
+func findNgfw(ctx context.Context, svc *api.ApiClient, name, vpcId string, tags []tag.Details) (string, error) {
+	tflog.Info(
+		ctx, "find ngfw",
+		map[string]interface{}{
+			"name":   name,
+			"vpc_id": vpcId,
+			"tags":   len(tags),
+		},
+	)
+
+	listing, err := listNgfws(ctx, svc, "", svc.GetRegion(ctx))
+	if err != nil {
+		return "", err
+	}
+
+	ids := make([]string, 0, 1)
 	for _, x := range listing {
-		instances = append(instances, map[string]interface{}{
-			"firewall_id": x.FirewallId,
-			"region":      x.Region,
-		})
+		if name == "" || x.Name == name {
+			ids = append(ids, x.FirewallId)
+		}
 	}
 
-	d.Set(RulestackName, stack)
-	d.Set("instances", instances)
+	if len(ids) > 0 && (vpcId != "" || len(tags) > 0) {
+		details, err := describeNgfws(ctx, svc, ids, findNgfwConcurrency)
+		if err != nil {
+			return "", err
+		}
+		ids = ids[:0]
+		for _, x := range details {
+			if x != nil && ngfwMatches(x.Firewall, vpcId, tags) {
+				ids = append(ids, x.Firewall.Id)
+			}
+		}
+	}
 
-	return nil
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("no NGFW found matching the given name, vpc_id and tags")
+	case 1:
+		return ids[0], nil
+	}
+
+	return "", fmt.Errorf("%d NGFWs match the given name, vpc_id and tags: %s", len(ids), strings.Join(ids, ", "))
+}
+
+// ngfwMatches returns true if the NGFW (or one of its endpoints) is in the
+// given VPC, and it has all of the given tags.
+func ngfwMatches(o ngfw.Info, vpcId string, tags []tag.Details) bool {
+	if vpcId != "" && o.VpcId != vpcId {
+		found := false
+		for _, ep := range o.Endpoints {
+			if ep.VpcId == vpcId {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	have := make(map[string]string, len(o.Tags))
+	for _, x := range o.Tags {
+		have[x.Key] = x.Value
+	}
+	for _, x := range tags {
+		if v, ok := have[x.Key]; !ok || v != x.Value {
+			return false
+		}
+	}
+
+	return true
 }
 
 // Data source for a single NGFW.
 func dataSourceNgfw() *schema.Resource {
 	return &schema.Resource{
-		Description: "Data source for retrieving NGFW information.",
+		Description: "Data source for retrieving NGFW information. The NGFW can be given by its ID, or looked up by its name, VPC and / or tags.",
 
 		ReadContext: readNgfwDataSource,
 
-		Schema: ngfwSchema(false, nil),
+		Schema: ngfwSchema(false, []string{"wait_for", "rejected_endpoint_action", "rollback_on_commit_failure", "force_disable_change_protection_on_destroy"}),
 	}
 }
 
 func readNgfwDataSource(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	svc := meta.(*api.ApiClient)
 
+	id := d.Get("firewall_id").(string)
+	if id == "" {
+		var err error
+		id, err = findNgfw(ctx, svc, d.Get("name").(string), d.Get("vpc_id").(string), loadTags(d.Get(TagsName)))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	req := ngfw.ReadInput{
-		FirewallId: d.Get("firewall_id").(string),
+		FirewallId: id,
 	}
 
 	tflog.Info(
 		ctx, "read ngfw",
 		map[string]interface{}{
 			"ds":         true,
-			"FirewallId": d.Id(),
+			"FirewallId": id,
 		},
 	)
 
@@ -161,9 +590,9 @@ func readNgfwDataSource(ctx context.Context, d *schema.ResourceData, meta interf
 		return diag.FromErr(err)
 	}
 
-	d.SetId(d.Id())
+	d.SetId(id)
 
-	if err := saveNgfw(ctx, d, res.Response); err != nil {
+	if err := saveNgfw(ctx, d, res.Response, false); err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -181,9 +610,11 @@ func resourceNgfw() *schema.Resource {
 		DeleteContext: deleteNgfw,
 
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: importNgfw,
 		},
 
+		CustomizeDiff: validateNgfw,
+
 		Schema: ngfwSchema(true, nil),
 		Timeouts: &schema.ResourceTimeout{
 			Create:  &resourceTimeout,
@@ -195,6 +626,44 @@ func resourceNgfw() *schema.Resource {
 	}
 }
 
+// validateNgfw checks the NGFW config at plan time, including refusing to
+// replace the NGFW while change protection would block deleting it.
+func validateNgfw(ctx context.Context, diff *schema.ResourceDiff, v interface{}) error {
+	if err := validateUserIdConfig(diff); err != nil {
+		return err
+	}
+
+	if err := validateEgressNat(diff); err != nil {
+		return err
+	}
+
+	// An empty change_protection is otherwise taken as unset, since the
+	// param is computed.
+	old, _ := diff.GetChange("change_protection")
+	if v := diff.GetRawConfig().GetAttr("change_protection"); v.IsWhollyKnown() && !v.IsNull() && v.LengthInt() == 0 {
+		if diff.Id() == "" || old.(*schema.Set).Len() > 0 {
+			if err := diff.SetNew("change_protection", []string{}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if diff.Id() == "" || !diff.HasChange("global_rulestack") {
+		return nil
+	}
+
+	if diff.Get("force_disable_change_protection_on_destroy").(bool) {
+		return nil
+	}
+
+	// Deleting happens with the change protection already in place.
+	if cp := setToSlice(old); len(cp) > 0 {
+		return fmt.Errorf("changing global_rulestack replaces the NGFW, which change protection %s blocks; set force_disable_change_protection_on_destroy, or apply change_protection = [] first", strings.Join(cp, ", "))
+	}
+
+	return nil
+}
+
 func createNgfw(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	svc := meta.(*api.ApiClient)
 	o, err := loadNgfw(d, nil)
@@ -214,7 +683,33 @@ func createNgfw(ctx context.Context, d *schema.ResourceData, meta interface{}) d
 	id := res.Response.Id
 	d.SetId(id)
 	d.Set("firewall_id", id)
-	return readNgfw(ctx, d, meta)
+
+	if err = waitForNgfw(ctx, svc, id, d.Get("wait_for").([]interface{})); err != nil {
+		return diag.FromErr(err)
+	}
+
+	diags := readNgfw(ctx, d, meta)
+	if diags.HasError() {
+		return diags
+	}
+
+	return append(diags, rejectedEndpointDiags(d)...)
+}
+
+func importNgfw(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	svc := meta.(*api.ApiClient)
+
+	res, err := svc.ReadFirewall(ctx, ngfw.ReadInput{FirewallId: d.Id()})
+	if err != nil {
+		return nil, err
+	}
+
+	// There is no config to tell which endpoints are managed yet, so take
+	// all of them, the following read only keeps these.
+	d.Set("firewall_id", d.Id())
+	saveEndpoints(d, res.Response, false)
+
+	return []*schema.ResourceData{d}, nil
 }
 
 func readNgfw(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -240,7 +735,7 @@ func readNgfw(ctx context.Context, d *schema.ResourceData, meta interface{}) dia
 		return diag.FromErr(err)
 	}
 
-	if err := saveNgfw(ctx, d, res.Response); err != nil {
+	if err := saveNgfw(ctx, d, res.Response, true); err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -249,31 +744,289 @@ func readNgfw(ctx context.Context, d *schema.ResourceData, meta interface{}) dia
 
 func updateNgfw(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	svc := meta.(*api.ApiClient)
+	id := d.Get("firewall_id").(string)
+	defer lockNgfw(id)()
+
 	req := ngfw.ReadInput{
-		FirewallId: d.Get("firewall_id").(string),
+		FirewallId: id,
 	}
 	res, err := svc.ReadFirewall(ctx, req)
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	curEps := res.Response.Firewall.Endpoints
-	o, err := loadNgfw(d, curEps)
+	cur := res.Response.Firewall
+	want, err := loadNgfw(d, cur.Endpoints)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
+	o, changed := mergeNgfwChanges(d, cur, want)
+
 	tflog.Info(
 		ctx, "update ngfw",
-		map[string]interface{}{},
+		map[string]interface{}{
+			"firewall_id": id,
+			"rulestack":   d.HasChange(RulestackName),
+			"changed":     changed,
+		},
 	)
 
-	err = svc.ModifyFirewallWithWait(ctx, o)
+	if d.HasChange(RulestackName) {
+		if err = swapNgfwRulestack(ctx, svc, cur, want.Rulestack, d.Get("rollback_on_commit_failure").(bool)); err != nil {
+			d.Partial(true)
+			return diag.FromErr(err)
+		}
+	}
+
+	if len(changed) > 0 {
+		if err = svc.ModifyFirewallWithWait(ctx, o); err != nil {
+			return diag.FromErr(err)
+		}
+	}
 
-	if err != nil {
+	if err = waitForNgfw(ctx, svc, id, d.Get("wait_for").([]interface{})); err != nil {
 		return diag.FromErr(err)
 	}
 
-	return readNgfw(ctx, d, meta)
+	diags := readNgfw(ctx, d, meta)
+	if diags.HasError() {
+		return diags
+	}
+
+	return append(diags, rejectedEndpointDiags(d)...)
+}
+
+// mergeNgfwChanges returns the NGFW update to send, which is the current config
+// of the NGFW with only the changed params taken from the plan, and the list
+// of params that changed.
+//
+// Sending the current config for everything else keeps endpoint prefixes,
+// egress NAT and user ID from being rewritten (and the NGFW from going through
+// a lengthy update) when they weren't touched.
+func mergeNgfwChanges(d *schema.ResourceData, cur, want ngfw.Info) (ngfw.Info, []string) {
+	o := ngfw.Info{
+		Id:                    cur.Id,
+		Description:           cur.Description,
+		Rulestack:             cur.Rulestack,
+		MultiVpc:              cur.MultiVpc,
+		EndpointMode:          cur.EndpointMode,
+		LinkId:                cur.LinkId,
+		LinkStatus:            cur.LinkStatus,
+		Tags:                  cur.Tags,
+		AllowListAccounts:     checkNilSlice(cur.AllowListAccounts),
+		ChangeProtection:      checkNilSlice(cur.ChangeProtection),
+		CustomerZoneIdList:    checkNilSlice(cur.CustomerZoneIdList),
+		Endpoints:             cur.Endpoints,
+		EgressNAT:             cur.EgressNAT,
+		PrivateAccess:         cur.PrivateAccess,
+		UserID:                cur.UserID,
+		UpdateToken:           cur.UpdateToken,
+		DeploymentUpdateToken: cur.DeploymentUpdateToken,
+	}
+
+	// The rulestack is associated separately beforehand, see updateNgfwRulestack.
+	if d.HasChange(RulestackName) {
+		o.Rulestack = want.Rulestack
+	}
+
+	changed := make([]string, 0)
+	apply := func(key string, fn func()) {
+		if d.HasChange(key) {
+			changed = append(changed, key)
+			fn()
+		}
+	}
+
+	apply("description", func() { o.Description = want.Description })
+	apply("multi_vpc", func() { o.MultiVpc = want.MultiVpc })
+	apply("endpoint_mode", func() { o.EndpointMode = want.EndpointMode })
+	apply("link_id", func() { o.LinkId = want.LinkId })
+	apply(TagsName, func() { o.Tags = want.Tags })
+	apply("allowlist_accounts", func() { o.AllowListAccounts = want.AllowListAccounts })
+	apply("change_protection", func() { o.ChangeProtection = want.ChangeProtection })
+	apply("az_list", func() { o.CustomerZoneIdList = want.CustomerZoneIdList })
+	apply("private_access", func() { o.PrivateAccess = want.PrivateAccess })
+	apply("user_id", func() { o.UserID = want.UserID })
+	apply("egress_nat", func() {
+		o.EgressNAT = want.EgressNAT
+		// Endpoints can't keep egress NAT once it's disabled on the NGFW.
+		if !want.EgressNAT.Enabled {
+			eps := make([]ngfw.EndpointConfig, 0, len(o.Endpoints))
+			for _, ep := range o.Endpoints {
+				ep.EgressNATEnabled = false
+				eps = append(eps, ep)
+			}
+			o.Endpoints = eps
+		}
+	})
+	apply("endpoints", func() {
+		prev, _ := d.GetChange("endpoints")
+		removed := stringSliceDifference(endpointSubnets(prev), endpointSubnets(d.Get("endpoints")))
+		o.Endpoints = mergeNgfwEndpoints(o.Endpoints, want.Endpoints, removed)
+	})
+
+	return o, changed
+}
+
+// mergeNgfwEndpoints returns the current endpoints with the endpoints in the
+// removed subnets dropped and the wanted endpoints added or replaced.
+//
+// Endpoints that are managed elsewhere (such as by cloudngfwaws_ngfw_endpoint)
+// are left as is.
+func mergeNgfwEndpoints(cur, want []ngfw.EndpointConfig, removed []string) []ngfw.EndpointConfig {
+	drop := make(map[string]bool, len(removed)+len(want))
+	for _, x := range removed {
+		drop[x] = true
+	}
+	for _, ep := range want {
+		drop[ep.SubnetId] = true
+	}
+
+	ans := make([]ngfw.EndpointConfig, 0, len(cur)+len(want))
+	for _, ep := range cur {
+		if !drop[ep.SubnetId] {
+			ans = append(ans, ep)
+		}
+	}
+
+	return append(ans, want...)
+}
+
+// endpointSubnets returns the subnet IDs of the given endpoints param.
+func endpointSubnets(v interface{}) []string {
+	list, _ := v.([]interface{})
+	ans := make([]string, 0, len(list))
+	for _, x := range list {
+		if ep, ok := x.(map[string]interface{}); ok {
+			if subnet, _ := ep["subnet_id"].(string); subnet != "" {
+				ans = append(ans, subnet)
+			}
+		}
+	}
+
+	return ans
+}
+
+// updateNgfwRulestack associates the NGFW with the given local rulestack, or
+// disassociates its current local rulestack if rs is empty.
+func updateNgfwRulestack(ctx context.Context, svc *api.ApiClient, cur ngfw.Info, rs string) error {
+	if rs == cur.Rulestack {
+		return nil
+	}
+
+	if rs == "" {
+		return svc.DisassociateRuleStackWithWait(ctx, ngfw.DisAssociateInput{
+			Firewall:    cur.Name,
+			AccountId:   cur.AccountId,
+			UpdateToken: cur.UpdateToken,
+			FirewallId:  cur.Id,
+		})
+	}
+
+	return svc.AssociateRulestackWithWait(ctx, ngfw.AssociateInput{
+		Firewall:    cur.Name,
+		Rulestack:   rs,
+		AccountId:   cur.AccountId,
+		UpdateToken: cur.UpdateToken,
+		FirewallId:  cur.Id,
+	})
+}
+
+// swapNgfwRulestack associates the NGFW with the given local rulestack.
+//
+// If rollback is set, it then waits for the rulestack to be committed on the
+// NGFW's devices, and if either step fails the NGFW is associated with its
+// previous rulestack again. The rollback gets its own timeout so that it still
+// happens when ctx is what ran out.
+func swapNgfwRulestack(ctx context.Context, svc *api.ApiClient, cur ngfw.Info, rs string, rollback bool) error {
+	if !rollback || rs == "" || rs == cur.Rulestack {
+		return updateNgfwRulestack(ctx, svc, cur, rs)
+	}
+
+	since := time.Now().UTC().Unix()
+	err := updateNgfwRulestack(ctx, svc, cur, rs)
+	if err == nil {
+		err = waitForDeviceRulestackCommit(ctx, svc, cur.Id, since)
+	}
+	if err == nil || cur.Rulestack == "" {
+		return err
+	}
+
+	tflog.Info(
+		ctx, "rolling back ngfw rulestack",
+		map[string]interface{}{
+			"firewall_id": cur.Id,
+			"failed":      rs,
+			RulestackName: cur.Rulestack,
+		},
+	)
+
+	rctx, cancel := context.WithTimeout(context.Background(), rollbackTimeout)
+	defer cancel()
+
+	res, rerr := svc.ReadFirewall(rctx, ngfw.ReadInput{FirewallId: cur.Id})
+	if rerr == nil {
+		rerr = updateNgfwRulestack(rctx, svc, res.Response.Firewall, cur.Rulestack)
+	}
+	if rerr != nil {
+		return fmt.Errorf("rulestack %q failed (%s) and rolling back to %q also failed: %s", rs, err, cur.Rulestack, rerr)
+	}
+
+	return fmt.Errorf("rulestack %q failed, rolled back to %q: %s", rs, cur.Rulestack, err)
+}
+
+// waitForDeviceRulestackCommit polls the NGFW until its devices report a
+// successful rulestack commit made after since (in epoch seconds), or until the
+// context's deadline.
+func waitForDeviceRulestackCommit(ctx context.Context, svc *api.ApiClient, id string, since int64) error {
+	interval := 10 * time.Second
+
+	for {
+		res, err := svc.ReadFirewall(ctx, ngfw.ReadInput{FirewallId: id})
+		if err != nil {
+			return err
+		}
+
+		stat := res.Response.Status
+		var msgs []string
+		var ts int64
+		if info := stat.DeviceRuleStackCommitInfo; info != nil {
+			msgs = info.CommitMessages
+			if info.CommitTS != "" {
+				if ts, err = aws.ConvertToUTCEpoch(info.CommitTS); err != nil {
+					return fmt.Errorf("invalid device rulestack commit timestamp %q: %s", info.CommitTS, err)
+				}
+			}
+		}
+
+		switch stat.DeviceRuleStackCommitStatus {
+		case "Failed":
+			return fmt.Errorf("device rulestack commit failed: %s", strings.Join(msgs, "; "))
+		case "Success":
+			if ts > since {
+				return nil
+			}
+		}
+
+		tflog.Info(
+			ctx, "waiting for device rulestack commit",
+			map[string]interface{}{
+				"firewall_id": id,
+				"status":      stat.DeviceRuleStackCommitStatus,
+			},
+		)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for the device rulestack commit, status is %q", stat.DeviceRuleStackCommitStatus)
+		case <-time.After(interval):
+		}
+
+		interval *= 2
+		if interval > time.Minute {
+			interval = time.Minute
+		}
+	}
 }
 
 func deleteNgfw(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -286,15 +1039,45 @@ func deleteNgfw(ctx context.Context, d *schema.ResourceData, meta interface{}) d
 		},
 	)
 
+	id := d.Get("firewall_id").(string)
+	res, err := svc.ReadFirewall(ctx, ngfw.ReadInput{FirewallId: id})
+	if err != nil {
+		if isObjectNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	cp := res.Response.Firewall.ChangeProtection
+	if len(cp) > 0 && d.Get("force_disable_change_protection_on_destroy").(bool) {
+		tflog.Info(
+			ctx, "disable ngfw change protection",
+			map[string]interface{}{
+				"firewall_id": id,
+			},
+		)
+
+		o := res.Response.Firewall
+		o.ChangeProtection = []string{}
+		if err = svc.ModifyFirewallWithWait(ctx, o); err != nil {
+			return diag.FromErr(err)
+		}
+		cp = nil
+	}
+
 	fw := ngfw.DeleteInput{
-		FirewallId: d.Get("firewall_id").(string),
+		FirewallId: id,
 	}
-	err := svc.DeleteFirewallWithWait(ctx, fw)
+	err = svc.DeleteFirewallWithWait(ctx, fw)
 	if err != nil {
 		if isObjectNotFound(err) {
 			d.SetId("")
 			return nil
 		}
+		if len(cp) > 0 {
+			return diag.Errorf("%s (NGFW %q has change protection %s enabled; set force_disable_change_protection_on_destroy or change_protection = [] and apply, then try again)", err, id, strings.Join(cp, ", "))
+		}
 		return diag.FromErr(err)
 	}
 
@@ -306,6 +1089,10 @@ func deleteNgfw(ctx context.Context, d *schema.ResourceData, meta interface{}) d
 func ngfwSchema(isResource bool, rmKeys []string) map[string]*schema.Schema {
 	endpoint_mode_opts := []string{"ServiceManaged", "CustomerManaged"}
 	ipPoolTypes := []string{"AWSService", "BYOIP"}
+	fwStatusOpts := []string{"CREATE_COMPLETE", "UPDATE_COMPLETE"}
+	rejectedOpts := []string{"error", "warn", "ignore"}
+	changeProtectionOpts := []string{"GlobalFirewallAdmin"}
+	rsStatusOpts := []string{"Success", "Pending"}
 	ans := map[string]*schema.Schema{
 		"name": {
 			Type:        schema.TypeString,
@@ -406,11 +1193,17 @@ func ngfwSchema(isResource bool, rmKeys []string) map[string]*schema.Schema {
 		"change_protection": {
 			Type: schema.TypeSet,
 			Elem: &schema.Schema{
-				Type: schema.TypeString,
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringInSlice(changeProtectionOpts, false),
 			},
 			Optional:    true,
 			Computed:    true,
-			Description: "Enables or disables change protection for the NGFW.",
+			Description: "Enables or disables change protection for the NGFW. The only valid value is `GlobalFirewallAdmin`, which is also enabled when the NGFW is created if this is unspecified. Set this to an empty list to disable change protection.",
+		},
+		"force_disable_change_protection_on_destroy": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Description: "Lift change protection before deleting the NGFW, since change protection can block deleting it.",
 		},
 		RulestackName: {
 			Type:        schema.TypeString,
@@ -491,12 +1284,13 @@ func ngfwSchema(isResource bool, rmKeys []string) map[string]*schema.Schema {
 					"port": {
 						Type:        schema.TypeInt,
 						Required:    true,
-						Description: "The Port",
+						Description: "The Port. This must be between 1 and 65535 when User-ID is enabled.",
 					},
 					"secret_key_arn": {
-						Type:        schema.TypeString,
-						Optional:    true,
-						Description: "AWS Secret Key ARN",
+						Type:         schema.TypeString,
+						Optional:     true,
+						Description:  "AWS Secret Key ARN. This must be a Secrets Manager secret ARN.",
+						ValidateFunc: validation.StringMatch(secretArnRegex, "must be a Secrets Manager secret ARN"),
 					},
 					"agent_name": {
 						Type:        schema.TypeString,
@@ -523,12 +1317,13 @@ func ngfwSchema(isResource bool, rmKeys []string) map[string]*schema.Schema {
 								"name": {
 									Type:        schema.TypeString,
 									Required:    true,
-									Description: "Name of subnet filter",
+									Description: "Name of subnet filter. Names must be unique within the user_id config.",
 								},
 								"network_address": {
-									Type:        schema.TypeString,
-									Required:    true,
-									Description: "Network IP address of the subnet filter",
+									Type:         schema.TypeString,
+									Required:     true,
+									Description:  "Network IP address of the subnet filter, in CIDR notation.",
+									ValidateFunc: validation.IsCIDR,
 								},
 								"discovery_include": {
 									Type:        schema.TypeBool,
@@ -575,13 +1370,71 @@ func ngfwSchema(isResource bool, rmKeys []string) map[string]*schema.Schema {
 			},
 		},
 		"endpoints": {
-			Type:     schema.TypeList,
-			Optional: true,
-			Elem:     endpointsSchemaResource(),
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "The endpoints of the NGFW. Only the endpoints in the subnets listed here are managed, other endpoints (such as those from `cloudngfwaws_ngfw_endpoint`) are left alone. Importing the NGFW manages all of its current endpoints.",
+			Elem:        endpointsSchemaResource(),
+		},
+		"rollback_on_commit_failure": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Description: "When changing the `rulestack`, wait for the new rulestack to be committed on the NGFW's devices, and associate the previous rulestack again if that fails. Without this, the rulestack is only associated, and its commit on the devices is not waited for.",
+		},
+		"rejected_endpoint_action": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Description:  addStringInSliceValidation("What to do after a create or update if any endpoint is rejected: fail the apply (`error`), report a warning for each rejected endpoint (`warn`), or do nothing (`ignore`). If unspecified, `warn` is used.", rejectedOpts),
+			ValidateFunc: validation.StringInSlice(rejectedOpts, false),
+		},
+		"wait_for": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			MaxItems:    1,
+			Description: "After a create or update, poll the NGFW with backoff until it reaches the given state, or until the resource timeout is reached. Use this to make sure traffic can flow through the NGFW before dependent resources (such as route tables) are changed.",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"firewall_status": {
+						Type:        schema.TypeSet,
+						Optional:    true,
+						Description: addStringInSliceValidation("Wait until the firewall status is one of these values.", fwStatusOpts),
+						Elem: &schema.Schema{
+							Type:         schema.TypeString,
+							ValidateFunc: validation.StringInSlice(fwStatusOpts, false),
+						},
+					},
+					"rulestack_status": {
+						Type:        schema.TypeSet,
+						Optional:    true,
+						Description: addStringInSliceValidation("Wait until the rulestack status is one of these values.", rsStatusOpts),
+						Elem: &schema.Schema{
+							Type:         schema.TypeString,
+							ValidateFunc: validation.StringInSlice(rsStatusOpts, false),
+						},
+					},
+					"endpoints_accepted": {
+						Type:        schema.TypeBool,
+						Optional:    true,
+						Description: "Wait until all endpoints are `Accepted`.",
+					},
+					"user_id_connected": {
+						Type:        schema.TypeBool,
+						Optional:    true,
+						Description: "Wait until `user_id_status` reports the User-ID agent as connected. This has no effect if User-ID is not enabled.",
+					},
+					"poll_interval": {
+						Type:         schema.TypeInt,
+						Optional:     true,
+						Description:  addIntBetweenValidation("The initial number of seconds between polls. This doubles after each poll, up to 60 seconds.", 1, 60),
+						Default:      10,
+						ValidateFunc: validation.IntBetween(1, 60),
+					},
+				},
+			},
 		},
 		"status": {
-			Type:     schema.TypeList,
-			Computed: true,
+			Type:        schema.TypeList,
+			Computed:    true,
+			Description: "The NGFW status. Its `public_ips` are the public IPs allocated for egress NAT, as a flat list rather than per AZ, since the SDK's PublicIP has no AZ field.",
 			Elem: &schema.Resource{
 				Schema: map[string]*schema.Schema{
 					"firewall_status": {
@@ -604,6 +1457,59 @@ func ngfwSchema(isResource bool, rmKeys []string) map[string]*schema.Schema {
 						Computed:    true,
 						Description: "The device rulestack commit status.",
 					},
+					"attachments": {
+						Type:        schema.TypeList,
+						Computed:    true,
+						Description: "The endpoint attachments.",
+						Elem: &schema.Resource{
+							Schema: map[string]*schema.Schema{
+								"endpoint_id": {
+									Type:        schema.TypeString,
+									Computed:    true,
+									Description: "The endpoint ID.",
+								},
+								"subnet_id": {
+									Type:        schema.TypeString,
+									Computed:    true,
+									Description: "The subnet ID.",
+								},
+								"status": {
+									Type:        schema.TypeString,
+									Computed:    true,
+									Description: "The attachment status.",
+								},
+								"rejected_reason": {
+									Type:        schema.TypeString,
+									Computed:    true,
+									Description: "The rejected reason.",
+								},
+							},
+						},
+					},
+					"public_ips": {
+						Type:        schema.TypeList,
+						Computed:    true,
+						Description: "The public IPs allocated to the NGFW for egress NAT. This is a flat list rather than per AZ, since the SDK's PublicIP has no AZ field.",
+						Elem: &schema.Resource{
+							Schema: map[string]*schema.Schema{
+								"ip_address": {
+									Type:        schema.TypeString,
+									Computed:    true,
+									Description: "The public IP address.",
+								},
+								"ip_status": {
+									Type:        schema.TypeString,
+									Computed:    true,
+									Description: "The status of the public IP.",
+								},
+								"ip_source": {
+									Type:        schema.TypeString,
+									Computed:    true,
+									Description: "Where the public IP was allocated from.",
+								},
+							},
+						},
+					},
 				},
 			},
 		},
@@ -615,8 +1521,18 @@ func ngfwSchema(isResource bool, rmKeys []string) map[string]*schema.Schema {
 
 	if !isResource {
 		computed(ans, "", make([]string, 0))
-		ans["firewall_id"].Computed = false
-		ans["firewall_id"].Required = true
+
+		lookupKeys := []string{"firewall_id", "name", "vpc_id", TagsName}
+		for _, key := range lookupKeys {
+			ans[key].Optional = true
+			ans[key].AtLeastOneOf = lookupKeys
+		}
+		ans["firewall_id"].Description = "The Firewall ID. If unspecified, the NGFW is looked up by `name`, `vpc_id` and / or `tags` instead."
+		ans["firewall_id"].ConflictsWith = []string{"name", "vpc_id", TagsName}
+		ans["vpc_id"].Description = "The VPC ID for the NGFW. When looking up the NGFW, this also matches the VPC of any of its endpoints."
+		ans[TagsName].Description = "The tags. When looking up the NGFW, it must have all of the given tags."
+		ans["endpoints"].Description = "The endpoints of the NGFW."
+		ans["change_protection"].Description = "The change protection enabled for the NGFW."
 	}
 
 	return ans
@@ -814,12 +1730,25 @@ func loadEgressNat(d *schema.ResourceData) (ngfw.EgressNATConfig, error) {
 func setChangeProtection(d *schema.ResourceData) []string {
 	changeProtection := setToSlice(d.Get("change_protection"))
 	changeProtection = checkNilSlice(changeProtection)
-	if len(changeProtection) == 0 {
+	if len(changeProtection) == 0 && !changeProtectionDisabled(d) {
+		// Matches the default documented on the change_protection param.
 		changeProtection = []string{"GlobalFirewallAdmin"}
 	}
 	return changeProtection
 }
 
+// changeProtectionDisabled returns if change_protection is explicitly set to
+// an empty list, as opposed to being unspecified.
+func changeProtectionDisabled(d *schema.ResourceData) bool {
+	cfg := d.GetRawConfig()
+	if cfg.IsNull() || !cfg.IsKnown() {
+		return false
+	}
+
+	v := cfg.GetAttr("change_protection")
+	return v.IsWhollyKnown() && !v.IsNull() && v.LengthInt() == 0
+}
+
 func setTags(d *schema.ResourceData) ([]tag.Details, error) {
 	fwName := d.Get("name").(string)
 	tags := loadTags(d.Get("tags"))
@@ -882,17 +1811,21 @@ func loadNgfw(d *schema.ResourceData, curEps []firewall.EndpointConfig) (ngfw.In
 }
 
 func saveEgressNat(ctx context.Context, d *schema.ResourceData, o ngfw.ReadResponse) {
+	if o.Firewall.EgressNAT == nil {
+		d.Set("egress_nat", nil)
+		return
+	}
+
 	egressNat := make([]interface{}, 0)
 	egressNatMap := make(map[string]interface{})
 	if o.Firewall.EgressNAT.Settings != nil {
-		egressNatMap["settings"] = []interface{}{
-			map[string]interface{}{
-				"ip_pool_type": o.Firewall.EgressNAT.Settings.IPPoolType,
-			},
+		settingsMap := map[string]interface{}{
+			"ip_pool_type": o.Firewall.EgressNAT.Settings.IPPoolType,
 		}
 		if o.Firewall.EgressNAT.Settings.IPAMPoolId != nil {
-			egressNatMap["ipam_pool_id"] = *o.Firewall.EgressNAT.Settings.IPAMPoolId
+			settingsMap["ipam_pool_id"] = *o.Firewall.EgressNAT.Settings.IPAMPoolId
 		}
+		egressNatMap["settings"] = []interface{}{settingsMap}
 	} else {
 		egressNatMap["settings"] = nil
 	}
@@ -951,9 +1884,23 @@ func savePrivateAccessConfig(d *schema.ResourceData, o ngfw.ReadResponse) {
 	}
 }
 
-func saveEndpoints(d *schema.ResourceData, o ngfw.ReadResponse) {
+// saveEndpoints saves the NGFW's endpoints.
+//
+// If managedOnly is set, only the endpoints in the subnets already listed in
+// the endpoints param are saved, in that order, so that endpoints created
+// outside of this resource don't show up as a diff.
+func saveEndpoints(d *schema.ResourceData, o ngfw.ReadResponse, managedOnly bool) {
 	resultEps := make([]interface{}, 0)
 	responseEps := o.Firewall.Endpoints
+	if managedOnly {
+		epMap := EpSubnetMap(responseEps)
+		responseEps = make([]ngfw.EndpointConfig, 0, len(epMap))
+		for _, subnet := range endpointSubnets(d.Get("endpoints")) {
+			if ep, ok := epMap[subnet]; ok {
+				responseEps = append(responseEps, ep)
+			}
+		}
+	}
 	if len(responseEps) > 0 {
 		for _, responseEp := range responseEps {
 			ep := make(map[string]interface{})
@@ -966,7 +1913,10 @@ func saveEndpoints(d *schema.ResourceData, o ngfw.ReadResponse) {
 			ep["status"] = responseEp.Status
 			ep["rejected_reason"] = responseEp.RejectedReason
 			ep["mode"] = responseEp.Mode
-			responseCidrs := responseEp.Prefixes.PrivatePrefix.Cidrs
+			var responseCidrs []string
+			if responseEp.Prefixes != nil {
+				responseCidrs = responseEp.Prefixes.PrivatePrefix.Cidrs
+			}
 			cidrs := make([]interface{}, 0)
 			if len(responseCidrs) > 0 {
 				for _, cidr := range responseCidrs {
@@ -1000,17 +1950,36 @@ func getFirewallName(tags []tag.Details) (string, error) {
 	return "", fmt.Errorf("firewall name wasn't found in tags")
 }
 
-func saveNgfw(ctx context.Context, d *schema.ResourceData, o ngfw.ReadResponse) error {
+func saveNgfw(ctx context.Context, d *schema.ResourceData, o ngfw.ReadResponse, isResource bool) error {
 	saveEgressNat(ctx, d, o)
-	saveEndpoints(d, o)
+	saveEndpoints(d, o, isResource)
 	saveUserIdConfig(d, o)
 	savePrivateAccessConfig(d, o)
+	attachments := make([]interface{}, 0, len(o.Status.Attachments))
+	for _, x := range o.Status.Attachments {
+		attachments = append(attachments, map[string]interface{}{
+			"endpoint_id":     x.EndpointId,
+			"subnet_id":       x.SubnetId,
+			"status":          x.Status,
+			"rejected_reason": x.RejectedReason,
+		})
+	}
+	publicIps := make([]interface{}, 0, len(o.Status.PublicIPs))
+	for _, x := range o.Status.PublicIPs {
+		publicIps = append(publicIps, map[string]interface{}{
+			"ip_address": x.IPAddress,
+			"ip_status":  x.IPStatus,
+			"ip_source":  x.IPSource,
+		})
+	}
 	stat := []interface{}{
 		map[string]interface{}{
 			"firewall_status":                o.Status.FirewallStatus,
 			"failure_reason":                 o.Status.FailureReason,
 			"rulestack_status":               o.Status.RulestackStatus,
 			"device_rulestack_commit_status": o.Status.DeviceRuleStackCommitStatus,
+			"attachments":                    attachments,
+			"public_ips":                     publicIps,
 		},
 	}
 	d.Set("firewall_id", o.Firewall.Id)
@@ -1037,6 +2006,159 @@ func saveNgfw(ctx context.Context, d *schema.ResourceData, o ngfw.ReadResponse)
 	return nil
 }
 
+// waitForNgfw polls the NGFW until it is in the state given by the wait_for
+// config, or until the context's deadline.
+func waitForNgfw(ctx context.Context, svc *api.ApiClient, id string, waitFor []interface{}) error {
+	if len(waitFor) == 0 || waitFor[0] == nil {
+		return nil
+	}
+
+	cfg := waitFor[0].(map[string]interface{})
+	fwStatuses := setToSlice(cfg["firewall_status"])
+	rsStatuses := setToSlice(cfg["rulestack_status"])
+	endpointsAccepted := cfg["endpoints_accepted"].(bool)
+	userIdConnected := cfg["user_id_connected"].(bool)
+	interval := time.Duration(cfg["poll_interval"].(int)) * time.Second
+
+	for {
+		res, err := svc.ReadFirewall(ctx, ngfw.ReadInput{FirewallId: id})
+		if err != nil {
+			return err
+		}
+
+		pending, err := ngfwPendingState(res.Response, fwStatuses, rsStatuses, endpointsAccepted, userIdConnected)
+		if err != nil {
+			return err
+		}
+		if pending == "" {
+			return nil
+		}
+
+		tflog.Info(
+			ctx, "waiting for ngfw",
+			map[string]interface{}{
+				"firewall_id": id,
+				"pending":     pending,
+				"interval":    interval.String(),
+			},
+		)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for ngfw %q: %s", id, pending)
+		case <-time.After(interval):
+		}
+
+		interval *= 2
+		if interval > time.Minute {
+			interval = time.Minute
+		}
+	}
+}
+
+// ngfwPendingState returns a description of what the NGFW is still waiting on,
+// or an empty string if it is in the desired state.
+func ngfwPendingState(o ngfw.ReadResponse, fwStatuses, rsStatuses []string, endpointsAccepted, userIdConnected bool) (string, error) {
+	if strings.HasSuffix(o.Status.FirewallStatus, "_FAIL") {
+		return "", fmt.Errorf("firewall status is %s: %s", o.Status.FirewallStatus, o.Status.FailureReason)
+	}
+
+	if len(fwStatuses) > 0 && !matchesAnyFold(o.Status.FirewallStatus, fwStatuses) {
+		return fmt.Sprintf("firewall status is %q", o.Status.FirewallStatus), nil
+	}
+
+	if len(rsStatuses) > 0 && !matchesAnyFold(o.Status.RulestackStatus, rsStatuses) {
+		return fmt.Sprintf("rulestack status is %q", o.Status.RulestackStatus), nil
+	}
+
+	if endpointsAccepted {
+		for _, ep := range o.Firewall.Endpoints {
+			switch ep.Status {
+			case "Accepted":
+			case "Rejected":
+				return "", fmt.Errorf("endpoint for subnet %q was rejected: %s", ep.SubnetId, ep.RejectedReason)
+			default:
+				return fmt.Sprintf("endpoint for subnet %q is %q", ep.SubnetId, ep.Status), nil
+			}
+		}
+		for _, x := range o.Status.Attachments {
+			switch x.Status {
+			case "Accepted":
+			case "Rejected":
+				return "", fmt.Errorf("attachment for subnet %q was rejected: %s", x.SubnetId, x.RejectedReason)
+			default:
+				return fmt.Sprintf("attachment for subnet %q is %q", x.SubnetId, x.Status), nil
+			}
+		}
+	}
+
+	if userIdConnected && o.Firewall.UserID != nil && o.Firewall.UserID.Enabled {
+		status := o.Firewall.UserID.UserIDStatus
+		lower := strings.ToLower(status)
+		switch {
+		case strings.Contains(lower, "fail") || strings.Contains(lower, "error"):
+			return "", fmt.Errorf("user_id status is %q", status)
+		case strings.Contains(lower, "disconnected") || strings.Contains(lower, "not connected"):
+			return fmt.Sprintf("user_id status is %q", status), nil
+		case !strings.Contains(lower, "connected"):
+			return fmt.Sprintf("user_id status is %q", status), nil
+		}
+	}
+
+	return "", nil
+}
+
+// rejectedEndpointDiags returns a diagnostic for each rejected endpoint or
+// attachment, according to rejected_endpoint_action.
+func rejectedEndpointDiags(d *schema.ResourceData) diag.Diagnostics {
+	// Unset is the same as "warn".
+	severity := diag.Warning
+	switch d.Get("rejected_endpoint_action").(string) {
+	case "ignore":
+		return nil
+	case "error":
+		severity = diag.Error
+	}
+
+	rejected := make(map[string]string)
+	var subnets []string
+	addRejected := func(subnet, status, reason string) {
+		if !strings.EqualFold(status, "Rejected") {
+			return
+		}
+		if _, ok := rejected[subnet]; !ok {
+			subnets = append(subnets, subnet)
+		}
+		if reason != "" || rejected[subnet] == "" {
+			rejected[subnet] = reason
+		}
+	}
+
+	for _, x := range d.Get("endpoints").([]interface{}) {
+		if ep, ok := x.(map[string]interface{}); ok {
+			addRejected(ep["subnet_id"].(string), ep["status"].(string), ep["rejected_reason"].(string))
+		}
+	}
+	if stat := d.Get("status").([]interface{}); len(stat) > 0 && stat[0] != nil {
+		for _, x := range stat[0].(map[string]interface{})["attachments"].([]interface{}) {
+			if att, ok := x.(map[string]interface{}); ok {
+				addRejected(att["subnet_id"].(string), att["status"].(string), att["rejected_reason"].(string))
+			}
+		}
+	}
+
+	var diags diag.Diagnostics
+	for _, subnet := range subnets {
+		diags = append(diags, diag.Diagnostic{
+			Severity: severity,
+			Summary:  fmt.Sprintf("Endpoint for subnet %q was rejected", subnet),
+			Detail:   fmt.Sprintf("Traffic for subnet %q is not protected by NGFW %q. Rejected reason: %s", subnet, d.Get("firewall_id").(string), rejected[subnet]),
+		})
+	}
+
+	return diags
+}
+
 // Id functions.
 func buildNgfwId(a, b string) string {
 	return strings.Join([]string{a, b}, IdSeparator)
@@ -0,0 +1,438 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api"
+	ngfw "github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api/firewall"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// Resource.
+func resourceNgfwEndpoint() *schema.Resource {
+	return &schema.Resource{
+		Description: "Resource for managing a single endpoint attachment of a NGFW. With `multi_vpc` enabled on the NGFW, this lets each VPC (or account) manage its own endpoint without editing the NGFW config.",
+
+		CreateContext: createNgfwEndpoint,
+		ReadContext:   readNgfwEndpoint,
+		UpdateContext: updateNgfwEndpoint,
+		DeleteContext: deleteNgfwEndpoint,
+
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: ngfwEndpointSchema(),
+		Timeouts: &schema.ResourceTimeout{
+			Create:  &resourceTimeout,
+			Read:    &resourceTimeout,
+			Update:  &resourceTimeout,
+			Delete:  &resourceTimeout,
+			Default: &resourceTimeout,
+		},
+	}
+}
+
+func createNgfwEndpoint(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	svc := meta.(*api.ApiClient)
+	fwId := d.Get("firewall_id").(string)
+	subnetId := d.Get("subnet_id").(string)
+
+	tflog.Info(
+		ctx, "create ngfw endpoint",
+		map[string]interface{}{
+			"firewall_id": fwId,
+			"subnet_id":   subnetId,
+		},
+	)
+
+	err := modifyNgfwEndpoints(ctx, svc, fwId, func(o *ngfw.Info) error {
+		for _, ep := range o.Endpoints {
+			if ep.SubnetId == subnetId {
+				return fmt.Errorf("an endpoint for subnet %q already exists on NGFW %q, import it instead", subnetId, fwId)
+			}
+		}
+		ep := loadNgfwEndpoint(d)
+		if !o.MultiVpc && ep.VpcId != "" && o.VpcId != "" && ep.VpcId != o.VpcId {
+			return fmt.Errorf("NGFW %q must have multi_vpc enabled for endpoints in VPC %q", fwId, ep.VpcId)
+		}
+		if err := checkEndpointEgressNat(o, ep); err != nil {
+			return err
+		}
+		o.Endpoints = append(o.Endpoints, ep)
+		return nil
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(buildNgfwEndpointId(fwId, subnetId))
+
+	if d.Get("wait_for_accepted").(bool) {
+		if err = waitForNgfwEndpoint(ctx, svc, fwId, subnetId); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return readNgfwEndpoint(ctx, d, meta)
+}
+
+func readNgfwEndpoint(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	svc := meta.(*api.ApiClient)
+
+	fwId, subnetId, err := parseNgfwEndpointId(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	tflog.Info(
+		ctx, "read ngfw endpoint",
+		map[string]interface{}{
+			"firewall_id": fwId,
+			"subnet_id":   subnetId,
+		},
+	)
+
+	res, err := svc.ReadFirewall(ctx, ngfw.ReadInput{FirewallId: fwId})
+	if err != nil {
+		if isObjectNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	for _, ep := range res.Response.Firewall.Endpoints {
+		if ep.SubnetId == subnetId {
+			saveNgfwEndpoint(d, fwId, ep)
+			return nil
+		}
+	}
+
+	d.SetId("")
+	return nil
+}
+
+func updateNgfwEndpoint(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	svc := meta.(*api.ApiClient)
+	fwId := d.Get("firewall_id").(string)
+	subnetId := d.Get("subnet_id").(string)
+
+	tflog.Info(
+		ctx, "update ngfw endpoint",
+		map[string]interface{}{
+			"firewall_id": fwId,
+			"subnet_id":   subnetId,
+		},
+	)
+
+	err := modifyNgfwEndpoints(ctx, svc, fwId, func(o *ngfw.Info) error {
+		for i, ep := range o.Endpoints {
+			if ep.SubnetId == subnetId {
+				x := loadNgfwEndpoint(d)
+				x.EndpointId = ep.EndpointId
+				if err := checkEndpointEgressNat(o, x); err != nil {
+					return err
+				}
+				o.Endpoints[i] = x
+				return nil
+			}
+		}
+		return fmt.Errorf("no endpoint for subnet %q found on NGFW %q", subnetId, fwId)
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return readNgfwEndpoint(ctx, d, meta)
+}
+
+func deleteNgfwEndpoint(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	svc := meta.(*api.ApiClient)
+	fwId := d.Get("firewall_id").(string)
+	subnetId := d.Get("subnet_id").(string)
+
+	tflog.Info(
+		ctx, "delete ngfw endpoint",
+		map[string]interface{}{
+			"firewall_id": fwId,
+			"subnet_id":   subnetId,
+		},
+	)
+
+	err := modifyNgfwEndpoints(ctx, svc, fwId, func(o *ngfw.Info) error {
+		eps := make([]ngfw.EndpointConfig, 0, len(o.Endpoints))
+		for _, ep := range o.Endpoints {
+			if ep.SubnetId != subnetId {
+				eps = append(eps, ep)
+			}
+		}
+		o.Endpoints = eps
+		return nil
+	})
+	if err != nil && !isObjectNotFound(err) {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// Schema handling.
+func ngfwEndpointSchema() map[string]*schema.Schema {
+	endpoint_mode_opts := []string{"ServiceManaged", "CustomerManaged"}
+
+	return map[string]*schema.Schema{
+		"firewall_id": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "The Firewall ID.",
+			ForceNew:    true,
+		},
+		"subnet_id": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "The subnet id.",
+			ForceNew:    true,
+		},
+		"mode": {
+			Type:         schema.TypeString,
+			Required:     true,
+			Description:  addStringInSliceValidation("The endpoint mode.", endpoint_mode_opts),
+			ForceNew:     true,
+			ValidateFunc: validation.StringInSlice(endpoint_mode_opts, false),
+		},
+		"account_id": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Computed:    true,
+			Description: "The account id.",
+			ForceNew:    true,
+		},
+		"vpc_id": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Computed:    true,
+			Description: "The vpc id.",
+			ForceNew:    true,
+		},
+		"zone_id": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Computed:    true,
+			Description: "The AZ id.",
+			ForceNew:    true,
+		},
+		"egress_nat_enabled": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Description: "Enable egress NAT. This requires egress NAT to be enabled on the NGFW.",
+		},
+		"private_prefix_cidrs": {
+			Type:        schema.TypeSet,
+			Optional:    true,
+			Computed:    true,
+			Description: "Additional private prefix CIDRs. The RFC 1918 CIDRs are always included.",
+			Elem: &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validateCidr,
+			},
+		},
+		"wait_for_accepted": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Description: "Wait for the endpoint to be accepted after it is created, failing if it is rejected.",
+			Default:     true,
+		},
+		"endpoint_id": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "The endpoint ID.",
+		},
+		"status": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "The attachment status.",
+		},
+		"rejected_reason": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "The rejected reason.",
+		},
+	}
+}
+
+func loadNgfwEndpoint(d *schema.ResourceData) ngfw.EndpointConfig {
+	prefixes := DefaultPrefixInfo()
+	for _, cidr := range setToSlice(d.Get("private_prefix_cidrs")) {
+		found := false
+		for _, x := range prefixes.PrivatePrefix.Cidrs {
+			if x == cidr {
+				found = true
+				break
+			}
+		}
+		if !found {
+			prefixes.PrivatePrefix.Cidrs = append(prefixes.PrivatePrefix.Cidrs, cidr)
+		}
+	}
+
+	return ngfw.EndpointConfig{
+		SubnetId:         d.Get("subnet_id").(string),
+		Mode:             d.Get("mode").(string),
+		AccountId:        d.Get("account_id").(string),
+		VpcId:            d.Get("vpc_id").(string),
+		ZoneId:           d.Get("zone_id").(string),
+		EgressNATEnabled: d.Get("egress_nat_enabled").(bool),
+		Prefixes:         prefixes,
+	}
+}
+
+func saveNgfwEndpoint(d *schema.ResourceData, fwId string, o ngfw.EndpointConfig) {
+	d.Set("firewall_id", fwId)
+	d.Set("subnet_id", o.SubnetId)
+	d.Set("mode", o.Mode)
+	d.Set("account_id", o.AccountId)
+	d.Set("vpc_id", o.VpcId)
+	d.Set("zone_id", o.ZoneId)
+	d.Set("egress_nat_enabled", o.EgressNATEnabled)
+	d.Set("private_prefix_cidrs", sliceToSet(ngfwEndpointCidrs(d, o)))
+	d.Set("endpoint_id", o.EndpointId)
+	d.Set("status", o.Status)
+	d.Set("rejected_reason", o.RejectedReason)
+}
+
+// ngfwEndpointCidrs returns the endpoint's private prefix CIDRs, leaving out
+// the RFC 1918 CIDRs that are always added unless they were configured.
+func ngfwEndpointCidrs(d *schema.ResourceData, o ngfw.EndpointConfig) []string {
+	if o.Prefixes == nil {
+		return nil
+	}
+
+	configured := make(map[string]bool)
+	for _, x := range setToSlice(d.Get("private_prefix_cidrs")) {
+		configured[x] = true
+	}
+	defaults := make(map[string]bool)
+	for _, x := range DefaultPrivPrefix {
+		defaults[x] = true
+	}
+
+	ans := make([]string, 0, len(o.Prefixes.PrivatePrefix.Cidrs))
+	for _, x := range o.Prefixes.PrivatePrefix.Cidrs {
+		if !defaults[x] || configured[x] {
+			ans = append(ans, x)
+		}
+	}
+
+	return ans
+}
+
+// modifyNgfwEndpoints reads the NGFW, lets fn change its endpoints, and
+// updates the NGFW.
+//
+// The NGFW is locked for the duration, so that multiple endpoint resources for
+// the same NGFW don't overwrite each other's changes.
+func modifyNgfwEndpoints(ctx context.Context, svc *api.ApiClient, fwId string, fn func(*ngfw.Info) error) error {
+	defer lockNgfw(fwId)()
+
+	res, err := svc.ReadFirewall(ctx, ngfw.ReadInput{FirewallId: fwId})
+	if err != nil {
+		return err
+	}
+
+	o := res.Response.Firewall
+	if err = fn(&o); err != nil {
+		return err
+	}
+
+	return svc.ModifyFirewallWithWait(ctx, o)
+}
+
+var ngfwLocks sync.Map
+
+// checkEndpointEgressNat returns an error if the endpoint has egress NAT
+// enabled but the NGFW does not.
+func checkEndpointEgressNat(o *ngfw.Info, ep ngfw.EndpointConfig) error {
+	if ep.EgressNATEnabled && (o.EgressNAT == nil || !o.EgressNAT.Enabled) {
+		return fmt.Errorf("NGFW %q must have egress NAT enabled for endpoints with egress_nat_enabled", o.Id)
+	}
+	return nil
+}
+
+// lockNgfw locks the given NGFW against concurrent modification by this
+// provider, returning the unlock func.
+func lockNgfw(fwId string) func() {
+	mu, _ := ngfwLocks.LoadOrStore(fwId, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	return mu.(*sync.Mutex).Unlock
+}
+
+// waitForNgfwEndpoint polls the NGFW until the endpoint for the given subnet
+// is accepted, or until the context's deadline.
+func waitForNgfwEndpoint(ctx context.Context, svc *api.ApiClient, fwId, subnetId string) error {
+	interval := 10 * time.Second
+
+	for {
+		res, err := svc.ReadFirewall(ctx, ngfw.ReadInput{FirewallId: fwId})
+		if err != nil {
+			return err
+		}
+
+		var status string
+		for _, ep := range res.Response.Firewall.Endpoints {
+			if ep.SubnetId != subnetId {
+				continue
+			}
+			switch {
+			case strings.EqualFold(ep.Status, "Accepted"):
+				return nil
+			case strings.EqualFold(ep.Status, "Rejected"):
+				return fmt.Errorf("endpoint for subnet %q was rejected: %s", subnetId, ep.RejectedReason)
+			}
+			status = ep.Status
+		}
+
+		tflog.Info(
+			ctx, "waiting for ngfw endpoint",
+			map[string]interface{}{
+				"firewall_id": fwId,
+				"subnet_id":   subnetId,
+				"status":      status,
+			},
+		)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for the endpoint for subnet %q to be accepted, status is %q", subnetId, status)
+		case <-time.After(interval):
+		}
+
+		interval *= 2
+		if interval > time.Minute {
+			interval = time.Minute
+		}
+	}
+}
+
+// Id functions.
+func buildNgfwEndpointId(a, b string) string {
+	return strings.Join([]string{a, b}, IdSeparator)
+}
+
+func parseNgfwEndpointId(v string) (string, string, error) {
+	tok := strings.Split(v, IdSeparator)
+	if len(tok) != 2 {
+		return "", "", fmt.Errorf("expecting 2 tokens, got %d", len(tok))
+	}
+
+	return tok[0], tok[1], nil
+}
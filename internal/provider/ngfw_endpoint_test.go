@@ -0,0 +1,94 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+// Resource.
+func TestAccResourceNgfwEndpoint(t *testing.T) {
+	vpcId, subnetId, azId := testAccNgfwNetwork(t)
+	name := fmt.Sprintf("tf%s", acctest.RandString(8))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccNgfwEndpointConfig(name, vpcId, subnetId, azId, ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(
+						"cloudngfwaws_ngfw_endpoint.test", "firewall_id",
+						"cloudngfwaws_ngfw.test", "firewall_id",
+					),
+					resource.TestCheckResourceAttr(
+						"cloudngfwaws_ngfw_endpoint.test", "subnet_id", subnetId,
+					),
+					resource.TestCheckResourceAttr(
+						"cloudngfwaws_ngfw_endpoint.test", "status", "Accepted",
+					),
+					resource.TestCheckResourceAttrSet(
+						"cloudngfwaws_ngfw_endpoint.test", "endpoint_id",
+					),
+				),
+			},
+			{
+				Config: testAccNgfwEndpointConfig(name, vpcId, subnetId, azId, "100.64.0.0/10"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"cloudngfwaws_ngfw_endpoint.test", "private_prefix_cidrs.#", "1",
+					),
+					resource.TestCheckTypeSetElemAttr(
+						"cloudngfwaws_ngfw_endpoint.test", "private_prefix_cidrs.*", "100.64.0.0/10",
+					),
+				),
+			},
+			{
+				ResourceName:            "cloudngfwaws_ngfw_endpoint.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"wait_for_accepted"},
+			},
+		},
+	})
+}
+
+// testAccNgfwNetwork returns the VPC, subnet and AZ ID to attach NGFW
+// endpoints to, skipping the test if they are not configured.
+func testAccNgfwNetwork(t *testing.T) (string, string, string) {
+	vpcId := os.Getenv("CLOUDNGFWAWS_VPC_ID")
+	subnetId := os.Getenv("CLOUDNGFWAWS_SUBNET_ID")
+	azId := os.Getenv("CLOUDNGFWAWS_AZ_ID")
+	if vpcId == "" || subnetId == "" || azId == "" {
+		t.Skip("CLOUDNGFWAWS_VPC_ID, CLOUDNGFWAWS_SUBNET_ID and CLOUDNGFWAWS_AZ_ID must be set for NGFW endpoint acctests")
+	}
+
+	return vpcId, subnetId, azId
+}
+
+func testAccNgfwEndpointConfig(name, vpcId, subnetId, azId, cidr string) string {
+	var cidrs string
+	if cidr != "" {
+		cidrs = fmt.Sprintf("\n    private_prefix_cidrs = [%q]", cidr)
+	}
+
+	return fmt.Sprintf(`
+resource "cloudngfwaws_ngfw_endpoint" "test" {
+    firewall_id = cloudngfwaws_ngfw.test.firewall_id
+    subnet_id = %q
+    vpc_id = %q
+    mode = "CustomerManaged"%s
+}
+
+resource "cloudngfwaws_ngfw" "test" {
+    name = %q
+    endpoint_mode = "CustomerManaged"
+    multi_vpc = true
+    az_list = [%q]
+}
+`, subnetId, vpcId, cidrs, name, azId)
+}
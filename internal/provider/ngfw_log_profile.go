@@ -3,11 +3,14 @@ package provider
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api"
+	ngfw "github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api/firewall"
 	lp "github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api/logprofile"
 
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
@@ -20,11 +23,61 @@ func dataSourceNgfwLogProfile() *schema.Resource {
 
 		ReadContext: readNgfwLogProfileDataSource,
 
-		Schema: ngfwLogProfileSchema(false, nil),
+		Schema: ngfwLogProfileSchema(false, []string{"retain_on_destroy"}),
 	}
 }
 
 func validateNgfwLogProfile(ctx context.Context, diff *schema.ResourceDiff, v interface{}) error {
+	if legacy := diff.Get("log_destination").([]interface{}); len(legacy) > 0 {
+		if len(diff.Get("log_config").([]interface{})) > 0 {
+			return fmt.Errorf("log_destination and log_config cannot both be set, move the log_destination entries into log_config")
+		}
+		known := true
+		for i := range legacy {
+			for _, key := range []string{"destination", "destination_type", "log_type"} {
+				if !diff.NewValueKnown(fmt.Sprintf("log_destination.%d.%s", i, key)) {
+					known = false
+				}
+			}
+		}
+		if known {
+			if _, err := legacyLogConfig(legacy); err != nil {
+				return err
+			}
+			for i, x := range legacy {
+				if m, _ := x.(map[string]interface{}); m != nil {
+					if err := validateLogDestination(m["destination_type"].(string), m["destination"].(string)); err != nil {
+						return fmt.Errorf("log_destination %d: %s", i, err)
+					}
+				}
+			}
+		}
+	}
+
+	if list := diff.Get("log_config").([]interface{}); len(list) > 0 {
+		for i, x := range list {
+			m, _ := x.(map[string]interface{})
+			if m == nil {
+				continue
+			}
+			known := true
+			for _, key := range []string{"log_destination", "log_destination_type", "log_type", "role_type", "account_id"} {
+				if !diff.NewValueKnown(fmt.Sprintf("log_config.%d.%s", i, key)) {
+					known = false
+				}
+			}
+			if !known {
+				continue
+			}
+			if err := validateLogDestination(m["log_destination_type"].(string), m["log_destination"].(string)); err != nil {
+				return fmt.Errorf("log_config %d: %s", i, err)
+			}
+			if err := validateLogAccount(m["log_destination_type"].(string), m["log_destination"].(string), m["account_id"].(string), m["role_type"].(string)); err != nil {
+				return fmt.Errorf("log_config %d: %s", i, err)
+			}
+		}
+	}
+
 	if diff.Get("account_id").(string) != "" || diff.Get("cloud_watch_metric_namespace").(string) != "" || len(diff.Get("cloudwatch_metric_fields").([]interface{})) > 0 {
 		if diff.Get("account_id").(string) == "" || diff.Get("cloud_watch_metric_namespace").(string) == "" {
 			return fmt.Errorf("if cloudwatch_metric_fields or account_id or cloud_watch_metric_namespace is set, both account_id and cloud_watch_metric_namespace must be set \nOr if you are using an old deployment please use provider version 2.0.20 or below")
@@ -37,6 +90,13 @@ func readNgfwLogProfileDataSource(ctx context.Context, d *schema.ResourceData, m
 	svc := meta.(*api.ApiClient)
 
 	firewallId := d.Get("firewall_id").(string)
+	if firewallId == "" {
+		var err error
+		firewallId, err = findNgfw(ctx, svc, d.Get("ngfw").(string), "", nil)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
 
 	req := lp.ReadInput{
 		FirewallId: firewallId,
@@ -69,11 +129,20 @@ func resourceNgfwLogProfile() *schema.Resource {
 		DeleteContext: deleteNgfwLogProfile,
 
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: importNgfwLogProfile,
 		},
 
 		Schema:        ngfwLogProfileSchema(true, nil),
 		CustomizeDiff: validateNgfwLogProfile,
+
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourceNgfwLogProfileV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: upgradeNgfwLogProfileV0,
+			},
+		},
 	}
 }
 
@@ -81,11 +150,20 @@ func createUpdateNgfwLogProfile(ctx context.Context, d *schema.ResourceData, met
 	svc := meta.(*api.ApiClient)
 	o := loadNgfwLogProfile(d)
 
+	if o.FirewallId == "" {
+		id, err := findNgfw(ctx, svc, d.Get("ngfw").(string), "", nil)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		o.FirewallId = id
+	}
+
 	if err := svc.UpdateFirewallLogProfile(ctx, o); err != nil {
 		return diag.FromErr(err)
 	}
 
 	d.SetId(buildNgfwLogProfileId("log_profile", o.FirewallId))
+	d.Set("firewall_id", o.FirewallId)
 
 	return readNgfwLogProfile(ctx, d, meta)
 }
@@ -113,11 +191,82 @@ func readNgfwLogProfile(ctx context.Context, d *schema.ResourceData, meta interf
 	return nil
 }
 
+// importNgfwLogProfile accepts either the NGFW's firewall ID or its name,
+// optionally in the log profile ID format.
+func importNgfwLogProfile(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	svc := meta.(*api.ApiClient)
+
+	ref := strings.TrimPrefix(d.Id(), "log_profile"+IdSeparator)
+	id, name, err := resolveNgfw(ctx, svc, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	d.SetId(buildNgfwLogProfileId("log_profile", id))
+	d.Set("firewall_id", id)
+	d.Set("ngfw", name)
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// resolveNgfw returns the firewall ID and name of the NGFW with the given
+// firewall ID or name.
+func resolveNgfw(ctx context.Context, svc *api.ApiClient, ref string) (string, string, error) {
+	if res, err := svc.ReadFirewall(ctx, ngfw.ReadInput{FirewallId: ref}); err == nil {
+		name, err := getFirewallName(res.Response.Firewall.Tags)
+		if err != nil {
+			name = res.Response.Firewall.Name
+		}
+		return ref, name, nil
+	}
+
+	id, err := findNgfw(ctx, svc, ref, "", nil)
+	if err != nil {
+		return "", "", fmt.Errorf("%q is neither a firewall ID nor the name of a single NGFW: %s", ref, err)
+	}
+
+	return id, ref, nil
+}
+
 func deleteNgfwLogProfile(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if d.Get("retain_on_destroy").(bool) {
+		d.SetId("")
+		return nil
+	}
+
+	svc := meta.(*api.ApiClient)
+	o := ngfwLogProfileReset(d)
+
+	tflog.Info(
+		ctx, "reset ngfw log profile",
+		map[string]interface{}{
+			"firewall_id": o.FirewallId,
+		},
+	)
+
+	if err := svc.UpdateFirewallLogProfile(ctx, o); err != nil && !isObjectNotFound(err) {
+		return diag.FromErr(err)
+	}
+
 	d.SetId("")
 	return nil
 }
 
+// ngfwLogProfileReset returns the log profile that sends no logs or metrics.
+//
+// A nil LogConfig is left out of the request entirely, which doesn't reliably
+// clear it, so empty ones are sent instead.  AdvancedThreatLog is omitted from
+// the request when false, so it can't be turned off and is left as is.
+func ngfwLogProfileReset(d *schema.ResourceData) lp.Info {
+	return lp.Info{
+		FirewallId:        d.Get("firewall_id").(string),
+		Region:            d.Get("region").(string),
+		UpdateToken:       d.Get("update_token").(string),
+		LogConfig:         &lp.LogConfig{},
+		CloudwatchMetrics: &lp.CloudwatchMetrics{},
+	}
+}
+
 // Schema handling.
 func ngfwLogProfileSchema(isResource bool, rmKeys []string) map[string]*schema.Schema {
 	destinationTypes := []string{"S3", "CloudWatchLogs", "KinesisDataFirehose"}
@@ -127,16 +276,20 @@ func ngfwLogProfileSchema(isResource bool, rmKeys []string) map[string]*schema.S
 		"BytesIn", "BytesOut", "PktsIn", "PktsOut"}
 	ans := map[string]*schema.Schema{
 		"ngfw": {
-			Type:        schema.TypeString,
-			Optional:    true,
-			Description: "The name of the NGFW.",
-			ForceNew:    true,
+			Type:         schema.TypeString,
+			Optional:     true,
+			Computed:     true,
+			Description:  "The name of the NGFW. The firewall ID is looked up by name if firewall_id is not set.",
+			ForceNew:     true,
+			AtLeastOneOf: []string{"ngfw", "firewall_id"},
 		},
 		"firewall_id": {
-			Type:        schema.TypeString,
-			Required:    true,
-			Description: "The Firewall Id for the NGFW.",
-			ForceNew:    true,
+			Type:         schema.TypeString,
+			Optional:     true,
+			Computed:     true,
+			Description:  "The Firewall Id for the NGFW.",
+			ForceNew:     true,
+			AtLeastOneOf: []string{"ngfw", "firewall_id"},
 		},
 		"account_id": {
 			Type:        schema.TypeString,
@@ -166,7 +319,8 @@ func ngfwLogProfileSchema(isResource bool, rmKeys []string) map[string]*schema.S
 		"log_destination": {
 			Type:        schema.TypeList,
 			Optional:    true,
-			Description: "List of log destinations.",
+			Description: "List of log destinations. All entries must share the same destination.",
+			Deprecated:  "Use log_config instead.",
 			MinItems:    1,
 			Elem: &schema.Resource{
 				Schema: map[string]*schema.Schema{
@@ -200,7 +354,7 @@ func ngfwLogProfileSchema(isResource bool, rmKeys []string) map[string]*schema.S
 					"log_destination": {
 						Type:        schema.TypeString,
 						Required:    true,
-						Description: "The log destination details.",
+						Description: "The log destination: an S3 bucket name, a CloudWatch log group name, or a Kinesis Data Firehose stream name or ARN, depending on the destination type.",
 					},
 					"log_destination_type": {
 						Type:         schema.TypeString,
@@ -219,12 +373,12 @@ func ngfwLogProfileSchema(isResource bool, rmKeys []string) map[string]*schema.S
 					"role_type": {
 						Type:        schema.TypeString,
 						Optional:    true,
-						Description: "Type of Role for log configuration",
+						Description: "Type of Role for log configuration. Requires account_id.",
 					},
 					"account_id": {
 						Type:        schema.TypeString,
 						Optional:    true,
-						Description: "Type of Role for log configuration",
+						Description: "The AWS account ID of the log destination. Required when role_type is set.",
 					},
 				},
 			},
@@ -234,6 +388,11 @@ func ngfwLogProfileSchema(isResource bool, rmKeys []string) map[string]*schema.S
 			Computed:    true,
 			Description: "The update token.",
 		},
+		"retain_on_destroy": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Description: "Leave the log profile on the NGFW as is when this resource is destroyed, instead of resetting it to send no logs or metrics. Advanced threat logging is left as is either way, since the API client can't turn it off.",
+		},
 		"region": {
 			Type:        schema.TypeString,
 			Optional:    true,
@@ -247,7 +406,9 @@ func ngfwLogProfileSchema(isResource bool, rmKeys []string) map[string]*schema.S
 	}
 
 	if !isResource {
-		computed(ans, "", []string{"ngfw", "account_id"})
+		computed(ans, "", []string{"ngfw", "firewall_id", "account_id"})
+		ans["ngfw"].ForceNew = false
+		ans["firewall_id"].ForceNew = false
 	}
 
 	return ans
@@ -257,7 +418,10 @@ func loadNgfwLogProfile(d *schema.ResourceData) lp.Info {
 	logProfile := lp.Info{}
 
 	list := d.Get("log_config").([]interface{})
-	if len(list) > 0 {
+	if legacy := d.Get("log_destination").([]interface{}); len(list) == 0 && len(legacy) > 0 {
+		// Already checked by validateNgfwLogProfile.
+		logProfile.LogConfig, _ = legacyLogConfig(legacy)
+	} else if len(list) > 0 {
 		x := list[0].(map[string]interface{})
 		logConfig := lp.LogConfig{}
 		logConfig.LogDestination = x["log_destination"].(string)
@@ -307,7 +471,10 @@ func loadNgfwLogProfile(d *schema.ResourceData) lp.Info {
 	return logProfile
 }
 func saveNgfwLogProfile(d *schema.ResourceData, o lp.Info) {
-	if o.LogConfig != nil {
+	if legacy := d.Get("log_destination").([]interface{}); len(legacy) > 0 {
+		d.Set("log_destination", dumpLegacyLogConfig(legacy, o.LogConfig))
+		d.Set("log_config", nil)
+	} else if o.LogConfig != nil {
 		logConfig := make([]interface{}, 0)
 		logConfigMap := make(map[string]interface{})
 		logConfigMap["log_destination"] = o.LogConfig.LogDestination
@@ -330,12 +497,261 @@ func saveNgfwLogProfile(d *schema.ResourceData, o lp.Info) {
 		}
 	}
 	d.Set("firewall_id", o.FirewallId)
+	if o.Firewall != "" {
+		d.Set("ngfw", o.Firewall)
+	}
 	d.Set("region", o.Region)
 	d.Set("advanced_threat_log", o.AdvancedThreatLog)
 	d.Set("update_token", o.UpdateToken)
 }
 
+var (
+	awsAccountIdRegex      = regexp.MustCompile(`^\d{12}$`)
+	s3BucketNameRegex      = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+	ipAddressRegex         = regexp.MustCompile(`^\d+\.\d+\.\d+\.\d+$`)
+	logGroupNameRegex      = regexp.MustCompile(`^[\w.\-/#]{1,512}$`)
+	firehoseStreamRegex    = regexp.MustCompile(`^[\w.\-]{1,64}$`)
+	firehoseStreamArnRegex = regexp.MustCompile(`^arn:aws[\w-]*:firehose:[a-z0-9-]+:(\d{12}):deliverystream/([\w.\-]{1,64})$`)
+)
+
+// validateLogDestination checks that the log destination is a valid name
+// for the given destination type.
+func validateLogDestination(destType, dest string) error {
+	switch destType {
+	case "S3":
+		if !s3BucketNameRegex.MatchString(dest) || strings.Contains(dest, "..") || ipAddressRegex.MatchString(dest) || strings.HasPrefix(dest, "xn--") || strings.HasSuffix(dest, "-s3alias") {
+			return fmt.Errorf("%q is not a valid S3 bucket name: it must be 3-63 lowercase letters, numbers, dots and hyphens, begin and end with a letter or number, and not be formatted as an IP address", dest)
+		}
+	case "CloudWatchLogs":
+		if !logGroupNameRegex.MatchString(dest) {
+			return fmt.Errorf("%q is not a valid CloudWatch log group name: it must be 1-512 letters, numbers, underscores, hyphens, periods, slashes and number signs", dest)
+		}
+	case "KinesisDataFirehose":
+		if !firehoseStreamRegex.MatchString(dest) && !firehoseStreamArnRegex.MatchString(dest) {
+			return fmt.Errorf("%q is not a valid Kinesis Data Firehose stream: it must be a stream name of 1-64 letters, numbers, underscores, hyphens and periods, or a delivery stream ARN", dest)
+		}
+	}
+
+	return nil
+}
+
+// validateLogAccount checks that the account ID and role type of a log config
+// agree with each other and with the destination.
+func validateLogAccount(destType, dest, accountId, roleType string) error {
+	if accountId == "" {
+		if roleType != "" {
+			return fmt.Errorf("role_type requires account_id to be set")
+		}
+		return nil
+	}
+
+	if !awsAccountIdRegex.MatchString(accountId) {
+		return fmt.Errorf("account_id %q is not a 12 digit AWS account ID", accountId)
+	}
+	if destType == "KinesisDataFirehose" {
+		if m := firehoseStreamArnRegex.FindStringSubmatch(dest); m != nil && m[1] != accountId {
+			return fmt.Errorf("the delivery stream %q is in account %s, not account_id %s", dest, m[1], accountId)
+		}
+	}
+
+	return nil
+}
+
+// legacyLogConfig translates the deprecated log_destination list into the
+// equivalent log config.
+func legacyLogConfig(list []interface{}) (*lp.LogConfig, error) {
+	var ans *lp.LogConfig
+	for i, x := range list {
+		m, _ := x.(map[string]interface{})
+		if m == nil {
+			return nil, fmt.Errorf("log_destination %d: destination, destination_type and log_type must be set", i)
+		}
+		dest, _ := m["destination"].(string)
+		destType, _ := m["destination_type"].(string)
+		logType, _ := m["log_type"].(string)
+		if dest == "" || destType == "" || logType == "" {
+			return nil, fmt.Errorf("log_destination %d: destination, destination_type and log_type must be set", i)
+		}
+
+		if ans == nil {
+			ans = &lp.LogConfig{
+				LogDestination:     dest,
+				LogDestinationType: destType,
+			}
+		} else if dest != ans.LogDestination || destType != ans.LogDestinationType {
+			return nil, fmt.Errorf("log_destination %d: all log_destination entries must share the same destination, use log_config instead", i)
+		}
+
+		found := false
+		for _, lt := range ans.LogType {
+			if lt == logType {
+				found = true
+				break
+			}
+		}
+		if !found {
+			ans.LogType = append(ans.LogType, logType)
+		}
+	}
+
+	return ans, nil
+}
+
+// dumpLegacyLogConfig returns the given log config as a log_destination list,
+// keeping the order of the log types in the current list.
+func dumpLegacyLogConfig(cur []interface{}, o *lp.LogConfig) []interface{} {
+	if o == nil {
+		return nil
+	}
+
+	remaining := make(map[string]bool, len(o.LogType))
+	for _, x := range o.LogType {
+		remaining[x] = true
+	}
+
+	order := make([]string, 0, len(o.LogType))
+	for _, x := range cur {
+		if m, ok := x.(map[string]interface{}); ok {
+			if lt, _ := m["log_type"].(string); remaining[lt] {
+				order = append(order, lt)
+				delete(remaining, lt)
+			}
+		}
+	}
+	for _, x := range o.LogType {
+		if remaining[x] {
+			order = append(order, x)
+			delete(remaining, x)
+		}
+	}
+
+	ans := make([]interface{}, 0, len(order))
+	for _, lt := range order {
+		ans = append(ans, map[string]interface{}{
+			"destination":      o.LogDestination,
+			"destination_type": o.LogDestinationType,
+			"log_type":         lt,
+		})
+	}
+
+	return ans
+}
+
 // Id functions.
 func buildNgfwLogProfileId(a, b string) string {
 	return strings.Join([]string{a, b}, IdSeparator)
 }
+
+// State upgrades.
+
+// resourceNgfwLogProfileV0 is the log profile schema as of version 0, when
+// log destinations were given using the legacy log_destination list.
+func resourceNgfwLogProfileV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"ngfw": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"firewall_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"account_id": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"cloud_watch_metric_namespace": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"advanced_threat_log": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+			"cloudwatch_metric_fields": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"log_destination": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"destination": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"destination_type": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"log_type": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+					},
+				},
+			},
+			"log_config": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"log_destination": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"log_destination_type": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"log_type": {
+							Type:     schema.TypeSet,
+							Required: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"role_type": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"account_id": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+					},
+				},
+			},
+			"update_token": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"region": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+		},
+	}
+}
+
+// upgradeNgfwLogProfileV0 matches the state to how it is read now.  Version 0
+// saved the log config read from the API into log_config even when the legacy
+// log_destination list was configured, so in that case log_config is dropped
+// and log_destination is kept as is.
+func upgradeNgfwLogProfileV0(ctx context.Context, rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+	if rawState == nil {
+		return rawState, nil
+	}
+
+	if list, _ := rawState["log_destination"].([]interface{}); len(list) > 0 {
+		delete(rawState, "log_config")
+	}
+
+	return rawState, nil
+}
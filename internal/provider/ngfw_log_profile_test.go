@@ -0,0 +1,353 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api"
+	ngfw "github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api/firewall"
+	lp "github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api/logprofile"
+	"github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api/response"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// Resource.
+func TestAccResourceNgfwLogProfile(t *testing.T) {
+	azId := os.Getenv("CLOUDNGFWAWS_AZ_ID")
+	bucket := os.Getenv("CLOUDNGFWAWS_LOG_BUCKET")
+	if azId == "" || bucket == "" {
+		t.Skip("CLOUDNGFWAWS_AZ_ID and CLOUDNGFWAWS_LOG_BUCKET must be set for NGFW log profile acctests")
+	}
+	name := fmt.Sprintf("tf%s", acctest.RandString(8))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccNgfwLogProfileConfig(name, azId, bucket),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(
+						"cloudngfwaws_ngfw_log_profile.test", "firewall_id",
+						"cloudngfwaws_ngfw.test", "firewall_id",
+					),
+					resource.TestCheckResourceAttr(
+						"cloudngfwaws_ngfw_log_profile.test", "log_config.0.log_destination", bucket,
+					),
+					resource.TestCheckResourceAttr(
+						"cloudngfwaws_ngfw_log_profile.test", "log_config.0.log_type.#", "2",
+					),
+				),
+			},
+			{
+				// Destroys the log profile, keeping the NGFW.
+				Config: testAccNgfwLogProfileResetConfig(name, azId),
+			},
+			{
+				// Reads the log profile again now that it has been reset.
+				Config: testAccNgfwLogProfileResetConfig(name, azId),
+				Check:  testAccCheckNgfwLogProfileReset("data.cloudngfwaws_ngfw_log_profile.test"),
+			},
+		},
+	})
+}
+
+// testAccCheckNgfwLogProfileReset checks that the log profile sends no logs.
+func testAccCheckNgfwLogProfileReset(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("%s not found", name)
+		}
+
+		attrs := rs.Primary.Attributes
+		if v := attrs["log_config.0.log_destination"]; v != "" {
+			return fmt.Errorf("log profile still sends logs to %q", v)
+		}
+		if v := attrs["log_config.0.log_type.#"]; v != "" && v != "0" {
+			return fmt.Errorf("log profile still has %s log types", v)
+		}
+
+		return nil
+	}
+}
+
+func testAccNgfwLogProfileConfig(name, azId, bucket string) string {
+	return fmt.Sprintf(`
+resource "cloudngfwaws_ngfw_log_profile" "test" {
+    ngfw = cloudngfwaws_ngfw.test.name
+    log_config {
+        log_destination = %q
+        log_destination_type = "S3"
+        log_type = ["TRAFFIC", "THREAT"]
+    }
+}
+
+resource "cloudngfwaws_ngfw" "test" {
+    name = %q
+    az_list = [%q]
+}
+`, bucket, name, azId)
+}
+
+func testAccNgfwLogProfileResetConfig(name, azId string) string {
+	return fmt.Sprintf(`
+data "cloudngfwaws_ngfw_log_profile" "test" {
+    firewall_id = cloudngfwaws_ngfw.test.firewall_id
+}
+
+resource "cloudngfwaws_ngfw" "test" {
+    name = %q
+    az_list = [%q]
+}
+`, name, azId)
+}
+
+func TestNgfwLogProfileStateUpgradeV0(t *testing.T) {
+	legacy := []interface{}{
+		map[string]interface{}{
+			"destination":      "my-bucket",
+			"destination_type": "S3",
+			"log_type":         "TRAFFIC",
+		},
+		map[string]interface{}{
+			"destination":      "my-bucket",
+			"destination_type": "S3",
+			"log_type":         "THREAT",
+		},
+	}
+	state := map[string]interface{}{
+		"firewall_id":     "fw-1234",
+		"log_destination": legacy,
+		"log_config": []interface{}{
+			map[string]interface{}{
+				"log_destination":      "my-bucket",
+				"log_destination_type": "S3",
+				"log_type":             []interface{}{"TRAFFIC", "THREAT"},
+			},
+		},
+	}
+
+	ans, err := upgradeNgfwLogProfileV0(context.Background(), state, nil)
+	if err != nil {
+		t.Fatalf("Error in upgrade: %s", err)
+	}
+
+	if _, ok := ans["log_config"]; ok {
+		t.Fatalf("Expected log_config to be dropped, got %#v", ans["log_config"])
+	}
+	if !reflect.DeepEqual(ans["log_destination"], legacy) {
+		t.Fatalf("Expected log_destination to be unchanged, got %#v", ans["log_destination"])
+	}
+}
+
+func TestNgfwLogProfileStateUpgradeV0KeepsLogConfig(t *testing.T) {
+	logConfig := []interface{}{
+		map[string]interface{}{
+			"log_destination":      "my-log-group",
+			"log_destination_type": "CloudWatchLogs",
+			"log_type":             []interface{}{"TRAFFIC"},
+		},
+	}
+	state := map[string]interface{}{
+		"firewall_id": "fw-1234",
+		"log_config":  logConfig,
+	}
+
+	ans, err := upgradeNgfwLogProfileV0(context.Background(), state, nil)
+	if err != nil {
+		t.Fatalf("Error in upgrade: %s", err)
+	}
+
+	if !reflect.DeepEqual(ans["log_config"], logConfig) {
+		t.Fatalf("Expected log_config to be unchanged, got %#v", ans["log_config"])
+	}
+}
+
+func TestNgfwLogProfileValidateLogDestination(t *testing.T) {
+	table := []struct {
+		destType string
+		dest     string
+		ok       bool
+	}{
+		{"S3", "my-log-bucket", true},
+		{"S3", "My_Bucket", false},
+		{"S3", "192.168.1.1", false},
+		{"S3", "my..bucket", false},
+		{"CloudWatchLogs", "/aws/ngfw/logs", true},
+		{"CloudWatchLogs", "bad:name", false},
+		{"KinesisDataFirehose", "my-stream", true},
+		{"KinesisDataFirehose", "arn:aws:firehose:us-east-1:123456789012:deliverystream/my-stream", true},
+		{"KinesisDataFirehose", "arn:aws:s3:::my-log-bucket", false},
+	}
+
+	for _, tc := range table {
+		err := validateLogDestination(tc.destType, tc.dest)
+		if tc.ok && err != nil {
+			t.Errorf("%s %q: unexpected error: %s", tc.destType, tc.dest, err)
+		} else if !tc.ok && err == nil {
+			t.Errorf("%s %q: expected an error", tc.destType, tc.dest)
+		}
+	}
+
+	arn := "arn:aws:firehose:us-east-1:123456789012:deliverystream/my-stream"
+	if err := validateLogAccount("KinesisDataFirehose", arn, "123456789012", ""); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if err := validateLogAccount("KinesisDataFirehose", arn, "210987654321", ""); err == nil {
+		t.Errorf("Expected an error for a mismatched account_id")
+	}
+	if err := validateLogAccount("S3", "my-log-bucket", "", "IamBased"); err == nil {
+		t.Errorf("Expected an error for role_type without account_id")
+	}
+}
+
+func TestResolveNgfw(t *testing.T) {
+	svc := testNgfwListClient()
+
+	table := []struct {
+		ref    string
+		id     string
+		name   string
+		errors bool
+	}{
+		{"fw-2", "fw-2", "b", false},
+		{"b", "fw-2", "b", false},
+		{"a", "", "", true},
+		{"c", "", "", true},
+	}
+
+	for _, tc := range table {
+		id, name, err := resolveNgfw(context.Background(), svc, tc.ref)
+		if tc.errors {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %q / %q", tc.ref, id, name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tc.ref, err)
+		} else if id != tc.id || name != tc.name {
+			t.Errorf("%s: expected %q / %q, got %q / %q", tc.ref, tc.id, tc.name, id, name)
+		}
+	}
+}
+
+// fakeLogProfileClient records the log profiles sent to it, and reads back
+// the last one sent for the NGFW.
+type fakeLogProfileClient struct {
+	fakeNgfwListClient
+
+	updates []lp.Info
+}
+
+func (c *fakeLogProfileClient) UpdateFirewallLogprofile(ctx context.Context, input lp.Info) error {
+	c.updates = append(c.updates, input)
+	return nil
+}
+
+func (c *fakeLogProfileClient) ReadFirewallLogprofile(ctx context.Context, input lp.ReadInput) (lp.ReadOutput, error) {
+	for i := len(c.updates) - 1; i >= 0; i-- {
+		if o := c.updates[i]; o.FirewallId == input.FirewallId {
+			return lp.ReadOutput{Response: &o}, nil
+		}
+	}
+	return lp.ReadOutput{}, &response.Status{Code: 404, Reason: "firewall does not exist"}
+}
+
+func TestCreateNgfwLogProfileByName(t *testing.T) {
+	c := &fakeLogProfileClient{
+		fakeNgfwListClient: fakeNgfwListClient{
+			fws: []ngfw.Info{
+				{Id: "fw-1", Name: "a"},
+				{Id: "fw-2", Name: "b"},
+			},
+		},
+	}
+	svc := api.NewAPIClient(c, context.Background(), 1, "", true)
+
+	d := schema.TestResourceDataRaw(t, resourceNgfwLogProfile().Schema, map[string]interface{}{
+		"ngfw": "b",
+		"log_config": []interface{}{
+			map[string]interface{}{
+				"log_destination":      "my-bucket",
+				"log_destination_type": "S3",
+				"log_type":             []interface{}{"TRAFFIC"},
+			},
+		},
+	})
+
+	if diags := createUpdateNgfwLogProfile(context.Background(), d, svc); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	if len(c.updates) != 1 || c.updates[0].FirewallId != "fw-2" {
+		t.Fatalf("expected the log profile of fw-2 to be updated, got %#v", c.updates)
+	}
+	if v := d.Get("firewall_id").(string); v != "fw-2" {
+		t.Errorf("firewall_id: expected %q, got %q", "fw-2", v)
+	}
+	if d.Id() != buildNgfwLogProfileId("log_profile", "fw-2") {
+		t.Errorf("unexpected id %q", d.Id())
+	}
+	if v := d.Get("log_config.0.log_destination").(string); v != "my-bucket" {
+		t.Errorf("log_destination: expected %q, got %q", "my-bucket", v)
+	}
+}
+
+func TestDeleteNgfwLogProfile(t *testing.T) {
+	raw := map[string]interface{}{
+		"firewall_id":         "fw-1",
+		"advanced_threat_log": true,
+		"log_config": []interface{}{
+			map[string]interface{}{
+				"log_destination":      "my-bucket",
+				"log_destination_type": "S3",
+				"log_type":             []interface{}{"TRAFFIC"},
+			},
+		},
+	}
+
+	for _, retain := range []bool{true, false} {
+		c := &fakeLogProfileClient{}
+		svc := api.NewAPIClient(c, context.Background(), 1, "", true)
+
+		raw["retain_on_destroy"] = retain
+		d := schema.TestResourceDataRaw(t, resourceNgfwLogProfile().Schema, raw)
+		d.SetId("log_profile:fw-1")
+
+		if diags := deleteNgfwLogProfile(context.Background(), d, svc); diags.HasError() {
+			t.Fatalf("retain %t: unexpected error: %v", retain, diags)
+		}
+		if d.Id() != "" {
+			t.Errorf("retain %t: expected the resource to be removed", retain)
+		}
+
+		if retain {
+			if len(c.updates) != 0 {
+				t.Errorf("retain %t: expected no update, got %#v", retain, c.updates)
+			}
+			continue
+		}
+		if len(c.updates) != 1 {
+			t.Fatalf("retain %t: expected one update, got %d", retain, len(c.updates))
+		}
+
+		b, err := json.Marshal(c.updates[0])
+		if err != nil {
+			t.Fatalf("retain %t: marshal: %s", retain, err)
+		}
+		want := `{"LogConfig":{},"CloudwatchMetrics":{},"FirewallId":"fw-1"}`
+		if string(b) != want {
+			t.Errorf("retain %t: expected request %s, got %s", retain, want, b)
+		}
+	}
+}
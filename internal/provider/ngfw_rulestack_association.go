@@ -0,0 +1,187 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api"
+	ngfw "github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api/firewall"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// Resource.
+func resourceNgfwRulestackAssociation() *schema.Resource {
+	return &schema.Resource{
+		Description: "Resource for associating a local rulestack with a NGFW, independently of the NGFW definition.",
+
+		CreateContext: createNgfwRulestackAssociation,
+		ReadContext:   readNgfwRulestackAssociation,
+		UpdateContext: updateNgfwRulestackAssociation,
+		DeleteContext: deleteNgfwRulestackAssociation,
+
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: ngfwRulestackAssociationSchema(),
+		Timeouts: &schema.ResourceTimeout{
+			Create:  &resourceTimeout,
+			Read:    &resourceTimeout,
+			Update:  &resourceTimeout,
+			Delete:  &resourceTimeout,
+			Default: &resourceTimeout,
+		},
+	}
+}
+
+func createNgfwRulestackAssociation(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	fwId := d.Get("firewall_id").(string)
+
+	if diags := associateNgfwRulestack(ctx, d, meta, "create"); diags.HasError() {
+		return diags
+	}
+
+	d.SetId(fwId)
+
+	return readNgfwRulestackAssociation(ctx, d, meta)
+}
+
+func readNgfwRulestackAssociation(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	svc := meta.(*api.ApiClient)
+	fwId := d.Id()
+
+	tflog.Info(
+		ctx, "read ngfw rulestack association",
+		map[string]interface{}{
+			"firewall_id": fwId,
+		},
+	)
+
+	res, err := svc.ReadFirewall(ctx, ngfw.ReadInput{FirewallId: fwId})
+	if err != nil {
+		if isObjectNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	if res.Response.Firewall.Rulestack == "" {
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("firewall_id", fwId)
+	d.Set(RulestackName, res.Response.Firewall.Rulestack)
+	d.Set("rulestack_status", res.Response.Status.RulestackStatus)
+	d.Set("device_rulestack_commit_status", res.Response.Status.DeviceRuleStackCommitStatus)
+
+	return nil
+}
+
+func updateNgfwRulestackAssociation(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := associateNgfwRulestack(ctx, d, meta, "update"); diags.HasError() {
+		return diags
+	}
+
+	return readNgfwRulestackAssociation(ctx, d, meta)
+}
+
+func deleteNgfwRulestackAssociation(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	svc := meta.(*api.ApiClient)
+	fwId := d.Get("firewall_id").(string)
+	rs := d.Get(RulestackName).(string)
+
+	tflog.Info(
+		ctx, "delete ngfw rulestack association",
+		map[string]interface{}{
+			"firewall_id": fwId,
+			RulestackName: rs,
+		},
+	)
+
+	defer lockNgfw(fwId)()
+
+	res, err := svc.ReadFirewall(ctx, ngfw.ReadInput{FirewallId: fwId})
+	if err != nil {
+		if isObjectNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	// Only disassociate the rulestack if it is still the one this resource
+	// associated.
+	if res.Response.Firewall.Rulestack == rs {
+		if err = updateNgfwRulestack(ctx, svc, res.Response.Firewall, ""); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// associateNgfwRulestack associates the configured rulestack with the NGFW.
+func associateNgfwRulestack(ctx context.Context, d *schema.ResourceData, meta interface{}, action string) diag.Diagnostics {
+	svc := meta.(*api.ApiClient)
+	fwId := d.Get("firewall_id").(string)
+	rs := d.Get(RulestackName).(string)
+
+	tflog.Info(
+		ctx, action+" ngfw rulestack association",
+		map[string]interface{}{
+			"firewall_id": fwId,
+			RulestackName: rs,
+		},
+	)
+
+	defer lockNgfw(fwId)()
+
+	res, err := svc.ReadFirewall(ctx, ngfw.ReadInput{FirewallId: fwId})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err = swapNgfwRulestack(ctx, svc, res.Response.Firewall, rs, d.Get("rollback_on_commit_failure").(bool)); err != nil {
+		d.Partial(true)
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+// Schema handling.
+func ngfwRulestackAssociationSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"firewall_id": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "The Firewall ID.",
+			ForceNew:    true,
+		},
+		RulestackName: {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "The local rulestack to associate with the NGFW.",
+		},
+		"rollback_on_commit_failure": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Description: "When changing the `rulestack`, wait for the new rulestack to be committed on the NGFW's devices, and associate the previous rulestack again if that fails. Without this, the rulestack is only associated, and its commit on the devices is not waited for.",
+		},
+		"rulestack_status": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "The rulestack status.",
+		},
+		"device_rulestack_commit_status": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "The device rulestack commit status.",
+		},
+	}
+}
@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+// Resource.
+func TestAccResourceNgfwRulestackAssociation(t *testing.T) {
+	azId := os.Getenv("CLOUDNGFWAWS_AZ_ID")
+	if azId == "" {
+		t.Skip("CLOUDNGFWAWS_AZ_ID must be set for NGFW rulestack association acctests")
+	}
+	name := fmt.Sprintf("tf%s", acctest.RandString(8))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccNgfwRulestackAssociationConfig(name, azId, "blue"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(
+						"cloudngfwaws_ngfw_rulestack_association.test", "firewall_id",
+						"cloudngfwaws_ngfw.test", "firewall_id",
+					),
+					resource.TestCheckResourceAttr(
+						"cloudngfwaws_ngfw_rulestack_association.test", "rulestack", name+"-blue",
+					),
+					resource.TestCheckResourceAttr(
+						"cloudngfwaws_ngfw_rulestack_association.test", "rulestack_status", "Success",
+					),
+				),
+			},
+			{
+				Config: testAccNgfwRulestackAssociationConfig(name, azId, "green"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"cloudngfwaws_ngfw_rulestack_association.test", "rulestack", name+"-green",
+					),
+					resource.TestCheckResourceAttr(
+						"cloudngfwaws_ngfw_rulestack_association.test", "device_rulestack_commit_status", "Success",
+					),
+				),
+			},
+			{
+				ResourceName:      "cloudngfwaws_ngfw_rulestack_association.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccNgfwRulestackAssociationConfig(name, azId, active string) string {
+	return fmt.Sprintf(`
+resource "cloudngfwaws_ngfw_rulestack_association" "test" {
+    firewall_id = cloudngfwaws_ngfw.test.firewall_id
+    rulestack = cloudngfwaws_commit_rulestack.test[%q].rulestack
+    rollback_on_commit_failure = true
+}
+
+resource "cloudngfwaws_commit_rulestack" "test" {
+    for_each = cloudngfwaws_rulestack.test
+    rulestack = each.value.name
+}
+
+resource "cloudngfwaws_rulestack" "test" {
+    for_each = toset(["blue", "green"])
+    name = "%s-${each.key}"
+    scope = "Local"
+    account_id = %q
+    account_group = %q
+    description = "Acctest description"
+    profile_config {
+        anti_spyware = "BestPractice"
+    }
+}
+
+resource "cloudngfwaws_ngfw" "test" {
+    name = %q
+    az_list = [%q]
+
+    lifecycle {
+        ignore_changes = [rulestack]
+    }
+}
+`, active, name, testAccAccountId, testAccAccountGroup, name, azId)
+}
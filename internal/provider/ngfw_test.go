@@ -0,0 +1,420 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api"
+	ngfw "github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api/firewall"
+	"github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api/response"
+	"github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api/tag"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestMergeNgfwChanges(t *testing.T) {
+	cur := ngfw.Info{
+		Id:          "fw-1",
+		Description: "old",
+		Rulestack:   "rs",
+		UpdateToken: "token",
+		Endpoints: []ngfw.EndpointConfig{
+			{SubnetId: "ext", EndpointId: "e1", EgressNATEnabled: true},
+		},
+		EgressNAT: &ngfw.EgressNATConfig{Enabled: true},
+		UserID:    &ngfw.UserIDConfig{Enabled: true, Port: 5007},
+	}
+
+	table := []struct {
+		name    string
+		raw     map[string]interface{}
+		want    ngfw.Info
+		changed []string
+		check   func(*testing.T, ngfw.Info)
+	}{
+		{
+			"only the description changed",
+			map[string]interface{}{"description": "new"},
+			ngfw.Info{Description: "new", Rulestack: "other"},
+			[]string{"description"},
+			func(t *testing.T, o ngfw.Info) {
+				if o.Description != "new" {
+					t.Errorf("description: expected %q, got %q", "new", o.Description)
+				}
+				if o.Rulestack != "rs" || o.UpdateToken != "token" {
+					t.Errorf("rulestack / update token not kept: %q / %q", o.Rulestack, o.UpdateToken)
+				}
+				if !reflect.DeepEqual(o.Endpoints, cur.Endpoints) || o.UserID != cur.UserID || o.EgressNAT != cur.EgressNAT {
+					t.Errorf("unchanged params were modified: %#v", o)
+				}
+			},
+		},
+		{
+			"endpoints are added next to external ones",
+			map[string]interface{}{
+				"endpoints": []interface{}{
+					map[string]interface{}{"subnet_id": "a", "mode": "ServiceManaged"},
+				},
+			},
+			ngfw.Info{Endpoints: []ngfw.EndpointConfig{{SubnetId: "a", Mode: "ServiceManaged"}}},
+			[]string{"endpoints"},
+			func(t *testing.T, o ngfw.Info) {
+				expect := []ngfw.EndpointConfig{cur.Endpoints[0], {SubnetId: "a", Mode: "ServiceManaged"}}
+				if !reflect.DeepEqual(o.Endpoints, expect) {
+					t.Errorf("endpoints: expected %#v, got %#v", expect, o.Endpoints)
+				}
+			},
+		},
+		{
+			"disabling egress nat clears it on the endpoints",
+			map[string]interface{}{
+				"egress_nat": []interface{}{
+					map[string]interface{}{"enabled": false},
+				},
+			},
+			ngfw.Info{EgressNAT: &ngfw.EgressNATConfig{}},
+			[]string{"egress_nat"},
+			func(t *testing.T, o ngfw.Info) {
+				if o.EgressNAT == nil || o.EgressNAT.Enabled {
+					t.Errorf("egress nat: expected disabled, got %#v", o.EgressNAT)
+				}
+				if len(o.Endpoints) != 1 || o.Endpoints[0].EgressNATEnabled {
+					t.Errorf("endpoints: expected egress nat disabled, got %#v", o.Endpoints)
+				}
+				if !cur.Endpoints[0].EgressNATEnabled {
+					t.Errorf("current endpoints were modified")
+				}
+			},
+		},
+	}
+
+	for _, tc := range table {
+		t.Run(tc.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, ngfwSchema(true, nil), tc.raw)
+			o, changed := mergeNgfwChanges(d, cur, tc.want)
+			if !reflect.DeepEqual(changed, tc.changed) {
+				t.Errorf("changed: expected %v, got %v", tc.changed, changed)
+			}
+			tc.check(t, o)
+		})
+	}
+}
+
+func TestMergeNgfwEndpoints(t *testing.T) {
+	ep := func(subnet, id string) ngfw.EndpointConfig {
+		return ngfw.EndpointConfig{SubnetId: subnet, EndpointId: id}
+	}
+
+	table := []struct {
+		name    string
+		cur     []ngfw.EndpointConfig
+		want    []ngfw.EndpointConfig
+		removed []string
+		expect  []ngfw.EndpointConfig
+	}{
+		{
+			"external endpoints are kept",
+			[]ngfw.EndpointConfig{ep("ext", "e1"), ep("a", "e2")},
+			[]ngfw.EndpointConfig{ep("a", "e2"), ep("b", "")},
+			nil,
+			[]ngfw.EndpointConfig{ep("ext", "e1"), ep("a", "e2"), ep("b", "")},
+		},
+		{
+			"removed endpoints are dropped",
+			[]ngfw.EndpointConfig{ep("ext", "e1"), ep("a", "e2"), ep("b", "e3")},
+			[]ngfw.EndpointConfig{ep("b", "e3")},
+			[]string{"a"},
+			[]ngfw.EndpointConfig{ep("ext", "e1"), ep("b", "e3")},
+		},
+		{
+			"all managed endpoints removed",
+			[]ngfw.EndpointConfig{ep("a", "e2"), ep("ext", "e1")},
+			nil,
+			[]string{"a"},
+			[]ngfw.EndpointConfig{ep("ext", "e1")},
+		},
+		{
+			"wanted endpoint replaces the current one",
+			[]ngfw.EndpointConfig{ep("a", "e2")},
+			[]ngfw.EndpointConfig{{SubnetId: "a", EndpointId: "e2", EgressNATEnabled: true}},
+			nil,
+			[]ngfw.EndpointConfig{{SubnetId: "a", EndpointId: "e2", EgressNATEnabled: true}},
+		},
+	}
+
+	for _, tc := range table {
+		t.Run(tc.name, func(t *testing.T) {
+			got := mergeNgfwEndpoints(tc.cur, tc.want, tc.removed)
+			if !reflect.DeepEqual(got, tc.expect) {
+				t.Errorf("expected %#v, got %#v", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestEndpointSubnets(t *testing.T) {
+	v := []interface{}{
+		map[string]interface{}{"subnet_id": "a"},
+		map[string]interface{}{"subnet_id": ""},
+		map[string]interface{}{"subnet_id": "b"},
+	}
+
+	got := endpointSubnets(v)
+	if !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("expected [a b], got %v", got)
+	}
+
+	if got := endpointSubnets(nil); len(got) != 0 {
+		t.Errorf("expected no subnets, got %v", got)
+	}
+}
+
+func TestSaveEndpoints(t *testing.T) {
+	o := ngfw.ReadResponse{
+		Firewall: ngfw.Info{
+			Endpoints: []ngfw.EndpointConfig{
+				{SubnetId: "ext", EndpointId: "e1"},
+				{SubnetId: "a", EndpointId: "e2"},
+			},
+		},
+	}
+
+	table := []struct {
+		name        string
+		raw         map[string]interface{}
+		managedOnly bool
+		want        []string
+	}{
+		{
+			"managed endpoints",
+			map[string]interface{}{
+				"endpoints": []interface{}{
+					map[string]interface{}{"subnet_id": "a"},
+				},
+			},
+			true,
+			[]string{"a"},
+		},
+		{"no managed endpoints", nil, true, []string{}},
+		{"import", nil, false, []string{"ext", "a"}},
+	}
+
+	for _, tc := range table {
+		t.Run(tc.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, ngfwSchema(true, nil), tc.raw)
+			saveEndpoints(d, o, tc.managedOnly)
+			if got := endpointSubnets(d.Get("endpoints")); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestNgfwMatches(t *testing.T) {
+	o := ngfw.Info{
+		VpcId: "vpc-1",
+		Endpoints: []ngfw.EndpointConfig{
+			{VpcId: "vpc-2"},
+		},
+		Tags: []tag.Details{
+			{Key: "FirewallName", Value: "fw"},
+			{Key: "env", Value: "prod"},
+		},
+	}
+
+	table := []struct {
+		name  string
+		vpcId string
+		tags  []tag.Details
+		want  bool
+	}{
+		{"no filters", "", nil, true},
+		{"ngfw vpc", "vpc-1", nil, true},
+		{"endpoint vpc", "vpc-2", nil, true},
+		{"other vpc", "vpc-3", nil, false},
+		{"matching tag", "", []tag.Details{{Key: "env", Value: "prod"}}, true},
+		{"tag value differs", "", []tag.Details{{Key: "env", Value: "dev"}}, false},
+		{"missing tag", "", []tag.Details{{Key: "team", Value: "net"}}, false},
+		{"vpc and tags", "vpc-2", []tag.Details{{Key: "FirewallName", Value: "fw"}, {Key: "env", Value: "prod"}}, true},
+		{"vpc matches but tags don't", "vpc-1", []tag.Details{{Key: "env", Value: "dev"}}, false},
+	}
+
+	for _, tc := range table {
+		if got := ngfwMatches(o, tc.vpcId, tc.tags); got != tc.want {
+			t.Errorf("%s: expected %t, got %t", tc.name, tc.want, got)
+		}
+	}
+}
+
+// fakeNgfwClient serves a single NGFW, only implementing the calls the tests
+// need.
+type fakeNgfwClient struct {
+	api.Client
+
+	fw           ngfw.Info
+	commitStatus string
+	commitTS     string
+	failOn       string
+	associated   []string
+	reads        int
+}
+
+func (c *fakeNgfwClient) ReadFirewall(ctx context.Context, input ngfw.ReadInput) (ngfw.ReadOutput, error) {
+	c.reads++
+	return ngfw.ReadOutput{
+		Response: ngfw.ReadResponse{
+			Firewall: c.fw,
+			Status: ngfw.FirewallStatus{
+				DeviceRuleStackCommitStatus: c.commitStatus,
+				DeviceRuleStackCommitInfo: &ngfw.RuleStackCommitData{
+					CommitMessages: []string{"commit message"},
+					CommitTS:       c.commitTS,
+				},
+			},
+		},
+	}, nil
+}
+
+func (c *fakeNgfwClient) AssociateRulestackWithWait(ctx context.Context, input ngfw.AssociateInput) error {
+	c.associated = append(c.associated, input.Rulestack)
+	if input.Rulestack == c.failOn {
+		return errors.New("associate failed")
+	}
+	c.fw.Rulestack = input.Rulestack
+	return nil
+}
+
+func TestSwapNgfwRulestack(t *testing.T) {
+	later := time.Now().Add(time.Hour).UTC().Format("2006-01-02T15:04:05") + " UTC"
+
+	table := []struct {
+		name         string
+		cur          string
+		rollback     bool
+		commitStatus string
+		failOn       string
+		associated   []string
+		final        string
+		errors       string
+	}{
+		{"no rollback", "blue", false, "Failed", "", []string{"green"}, "green", ""},
+		{"commit succeeds", "blue", true, "Success", "", []string{"green"}, "green", ""},
+		{"commit fails", "blue", true, "Failed", "", []string{"green", "blue"}, "blue", "rolled back"},
+		{"associate fails", "blue", true, "Success", "green", []string{"green"}, "blue", "rolled back"},
+		{"rollback fails", "blue", true, "Failed", "blue", []string{"green", "blue"}, "green", "also failed"},
+		{"no previous rulestack", "", true, "Failed", "", []string{"green"}, "green", "commit failed"},
+	}
+
+	for _, tc := range table {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &fakeNgfwClient{
+				fw:           ngfw.Info{Id: "fw-1", Rulestack: tc.cur},
+				commitStatus: tc.commitStatus,
+				commitTS:     later,
+				failOn:       tc.failOn,
+			}
+			svc := api.NewAPIClient(c, context.Background(), 1, "", true)
+
+			err := swapNgfwRulestack(context.Background(), svc, c.fw, "green", tc.rollback)
+			switch {
+			case tc.errors == "" && err != nil:
+				t.Fatalf("unexpected error: %s", err)
+			case tc.errors != "" && (err == nil || !strings.Contains(err.Error(), tc.errors)):
+				t.Fatalf("expected an error containing %q, got %v", tc.errors, err)
+			}
+			if !reflect.DeepEqual(c.associated, tc.associated) {
+				t.Errorf("associated: expected %v, got %v", tc.associated, c.associated)
+			}
+			if c.fw.Rulestack != tc.final {
+				t.Errorf("rulestack: expected %q, got %q", tc.final, c.fw.Rulestack)
+			}
+			if !tc.rollback && c.reads != 0 {
+				t.Errorf("expected no wait for the device commit, got %d reads", c.reads)
+			}
+		})
+	}
+}
+
+// fakeNgfwListClient serves the given NGFWs for listing and reading.
+type fakeNgfwListClient struct {
+	api.Client
+
+	fws []ngfw.Info
+}
+
+func (c *fakeNgfwListClient) GetRegion(ctx context.Context) string {
+	return "us-east-1"
+}
+
+func (c *fakeNgfwListClient) ListFirewall(ctx context.Context, input ngfw.ListInput) (ngfw.ListOutput, error) {
+	var ans ngfw.ListOutput
+	for _, x := range c.fws {
+		ans.Response.Firewalls = append(ans.Response.Firewalls, ngfw.ListFirewall{
+			Name:       x.Name,
+			FirewallId: x.Id,
+		})
+	}
+	return ans, nil
+}
+
+func (c *fakeNgfwListClient) ReadFirewall(ctx context.Context, input ngfw.ReadInput) (ngfw.ReadOutput, error) {
+	for _, x := range c.fws {
+		if x.Id == input.FirewallId {
+			return ngfw.ReadOutput{Response: ngfw.ReadResponse{Firewall: x}}, nil
+		}
+	}
+	return ngfw.ReadOutput{}, &response.Status{Code: 404, Reason: "firewall does not exist"}
+}
+
+func testNgfwListClient() *api.ApiClient {
+	fw := func(id, name, vpcId string, tags ...tag.Details) ngfw.Info {
+		tags = append(tags, tag.Details{Key: "FirewallName", Value: name})
+		return ngfw.Info{Id: id, Name: name, VpcId: vpcId, Tags: tags}
+	}
+
+	c := &fakeNgfwListClient{
+		fws: []ngfw.Info{
+			fw("fw-1", "a", "vpc-1", tag.Details{Key: "env", Value: "prod"}),
+			fw("fw-2", "b", "vpc-2"),
+			fw("fw-3", "a", "vpc-3"),
+		},
+	}
+
+	return api.NewAPIClient(c, context.Background(), 1, "", true)
+}
+
+func TestFindNgfw(t *testing.T) {
+	svc := testNgfwListClient()
+
+	table := []struct {
+		name  string
+		fw    string
+		vpcId string
+		tags  []tag.Details
+		want  string
+	}{
+		{"name", "b", "", nil, "fw-2"},
+		{"name and vpc", "a", "vpc-3", nil, "fw-3"},
+		{"vpc", "", "vpc-1", nil, "fw-1"},
+		{"tags", "", "", []tag.Details{{Key: "env", Value: "prod"}}, "fw-1"},
+		{"several match", "a", "", nil, ""},
+		{"none match", "a", "vpc-2", nil, ""},
+		{"unknown name", "c", "", nil, ""},
+	}
+
+	for _, tc := range table {
+		got, err := findNgfw(context.Background(), svc, tc.fw, tc.vpcId, tc.tags)
+		switch {
+		case tc.want == "" && err == nil:
+			t.Errorf("%s: expected an error, got %q", tc.name, got)
+		case tc.want != "" && err != nil:
+			t.Errorf("%s: unexpected error: %s", tc.name, err)
+		case got != tc.want:
+			t.Errorf("%s: expected %q, got %q", tc.name, tc.want, got)
+		}
+	}
+}
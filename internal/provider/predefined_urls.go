@@ -3,6 +3,8 @@ package provider
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -26,23 +28,39 @@ func dataSourcePredefinedUrlCategories() *schema.Resource {
 			"token": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				Description: "Pagination token.",
+				Description: "Pagination token to start from.",
+				Deprecated:  "All pages are now retrieved automatically, so this param is no longer needed.",
 			},
 			"max_results": {
 				Type:        schema.TypeInt,
 				Optional:    true,
-				Description: "Max results.",
+				Description: "Max results per page. All pages are retrieved.",
 				Default:     100,
 			},
+			"name_regex": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Only return categories whose name matches this regular expression.",
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
 			"next_token": {
 				Type:        schema.TypeString,
 				Computed:    true,
 				Description: "Next pagination token.",
+				Deprecated:  "All pages are now retrieved automatically, so this attribute is always empty.",
 			},
 			"categories": {
 				Type:        schema.TypeList,
 				Computed:    true,
-				Description: "List of predefined URL categories.",
+				Description: "List of predefined URL category names, sorted by name.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"actions": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "The predefined URL category name (as the key) and action (as the value).",
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
@@ -54,37 +72,83 @@ func dataSourcePredefinedUrlCategories() *schema.Resource {
 func readPredefinedUrlCategories(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	svc := meta.(*api.ApiClient)
 
-	input := url.ListInput{
-		NextToken:  d.Get("token").(string),
-		MaxResults: d.Get("max_results").(int),
-	}
-
-	d.Set("token", input.NextToken)
-	d.Set("max_results", input.MaxResults)
+	nt := d.Get("token").(string)
+	maxResults := d.Get("max_results").(int)
+	nameRegex := d.Get("name_regex").(string)
 
 	tflog.Info(
 		ctx, "read predefined url categories",
 		map[string]interface{}{
 			"ds":          true,
-			"token":       input.NextToken,
-			"max_results": input.MaxResults,
+			"token":       nt,
+			"max_results": maxResults,
+			"name_regex":  nameRegex,
 		},
 	)
 
-	ans, err := svc.ListUrlPredefinedCategories(ctx, input)
+	id := strings.Join(
+		[]string{nt, strconv.Itoa(maxResults)}, IdSeparator,
+	)
+	d.Set("token", nt)
+	d.Set("max_results", maxResults)
+
+	listing, err := listPredefinedUrlCategories(ctx, svc, maxResults, nt)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	d.SetId(strings.Join(
-		[]string{input.NextToken, strconv.Itoa(input.MaxResults)}, IdSeparator,
-	))
-	d.Set("next_token", ans.Response.NextToken)
-	d.Set("categories", ans.Response.Categories)
+	var re *regexp.Regexp
+	if nameRegex != "" {
+		re = regexp.MustCompile(nameRegex)
+	}
+
+	sort.Slice(listing, func(i, j int) bool {
+		return listing[i].Name < listing[j].Name
+	})
+
+	categories := make([]string, 0, len(listing))
+	var actions map[string]interface{}
+	for _, x := range listing {
+		if re != nil && !re.MatchString(x.Name) {
+			continue
+		}
+		if actions == nil {
+			actions = make(map[string]interface{})
+		}
+		categories = append(categories, x.Name)
+		actions[x.Name] = x.Action
+	}
+
+	d.SetId(id)
+	d.Set("next_token", "")
+	d.Set("categories", categories)
+	d.Set("actions", actions)
 
 	return nil
 }
 
+// listPredefinedUrlCategories returns all predefined URL categories starting
+// from the given pagination token.
+func listPredefinedUrlCategories(ctx context.Context, svc *api.ApiClient, maxResults int, nt string) ([]url.Category, error) {
+	var listing []url.Category
+	for {
+		input := url.ListInput{
+			NextToken:  nt,
+			MaxResults: maxResults,
+		}
+		ans, err := svc.ListUrlPredefinedCategories(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+
+		listing = append(listing, ans.Response.Categories...)
+		if ans.Response.NextToken == "" || ans.Response.NextToken == nt {
+			return listing, nil
+		}
+		nt = ans.Response.NextToken
+	}
+}
+
 // Data source (predefined url category override).
 func dataSourcePredefinedUrlCategoryOverride() *schema.Resource {
 	return &schema.Resource{
@@ -17,6 +17,10 @@ import (
 
 var resourceTimeout = 120 * time.Minute
 
+// rollbackTimeout bounds reverting a failed rulestack swap, which may happen
+// after the resource's own timeout has run out.
+var rollbackTimeout = 30 * time.Minute
+
 func init() {
 	schema.DescriptionKind = schema.StringMarkdown
 
@@ -43,6 +47,8 @@ func New(version string) func() *schema.Provider {
 			DataSourcesMap: map[string]*schema.Resource{
 				"cloudngfwaws_app_id_version":                   dataSourceAppIdVersion(),
 				"cloudngfwaws_app_id_versions":                  dataSourceAppIdVersions(),
+				"cloudngfwaws_app_id_version_diff":              dataSourceAppIdVersionDiff(),
+				"cloudngfwaws_application_group":                dataSourceApplicationGroup(),
 				"cloudngfwaws_certificate":                      dataSourceCertificate(),
 				"cloudngfwaws_country":                          dataSourceCountry(),
 				"cloudngfwaws_custom_url_category":              dataSourceCustomUrlCategory(),
@@ -67,7 +73,9 @@ func New(version string) func() *schema.Provider {
 				"cloudngfwaws_custom_url_category":              resourceCustomUrlCategory(),
 				"cloudngfwaws_fqdn_list":                        resourceFqdnList(),
 				"cloudngfwaws_ngfw":                             resourceNgfw(),
+				"cloudngfwaws_ngfw_endpoint":                    resourceNgfwEndpoint(),
 				"cloudngfwaws_ngfw_log_profile":                 resourceNgfwLogProfile(),
+				"cloudngfwaws_ngfw_rulestack_association":       resourceNgfwRulestackAssociation(),
 				"cloudngfwaws_intelligent_feed":                 resourceIntelligentFeed(),
 				"cloudngfwaws_predefined_url_category_override": resourcePredefinedUrlCategoryOverride(),
 				"cloudngfwaws_prefix_list":                      resourcePrefixList(),
@@ -278,83 +286,99 @@ func providerSchema() map[string]*schema.Schema {
 	}
 }
 
+// providerConfig is the provider configuration, as given to either the SDK
+// or the framework provider.
+type providerConfig interface {
+	Get(string) interface{}
+}
+
 func configure(version string, p *schema.Provider) func(context.Context, *schema.ResourceData) (interface{}, diag.Diagnostics) {
 	return func(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
-		var lc uint32
-
-		lm := map[string]uint32{
-			"quiet":   ngfw.LogQuiet,
-			"login":   ngfw.LogLogin,
-			"get":     ngfw.LogGet,
-			"patch":   ngfw.LogPatch,
-			"post":    ngfw.LogPost,
-			"put":     ngfw.LogPut,
-			"delete":  ngfw.LogDelete,
-			"action":  ngfw.LogAction,
-			"path":    ngfw.LogPath,
-			"send":    ngfw.LogSend,
-			"receive": ngfw.LogReceive,
+		con, err := newAwsClient(d, p.UserAgent("terraform-provider-cloudngfwaws", version))
+		if err != nil {
+			return nil, diag.FromErr(err)
 		}
 
-		var hdrs map[string]string
-		hconfig := d.Get("headers").(map[string]interface{})
-		if len(hconfig) > 0 {
-			hdrs = make(map[string]string)
-			for key, val := range hconfig {
-				hdrs[key] = val.(string)
-			}
-		}
+		apiClient := api.NewAPIClient(con, ctx, 5000, "", false)
+		getSessionCache(apiClient).con = con
+		api.Logger.Infof("sync_mode:%+v", apiClient.IsSyncModeEnabled(ctx))
+		return apiClient, nil
+	}
+}
 
-		if ll := d.Get("logging").([]interface{}); len(ll) > 0 {
-			for i := range ll {
-				s := ll[i].(string)
-				if v, ok := lm[s]; !ok {
-					return nil, diag.Errorf("Unknown logging artifact specified: %s", s)
-				} else {
-					lc |= v
-				}
-			}
-		}
+func newAwsClient(d providerConfig, agent string) (*aws.Client, error) {
+	var lc uint32
 
-		con := &aws.Client{
-			Host:                  d.Get("host").(string),
-			MPRegionHost:          d.Get("mp_region_host").(string),
-			V2Host:                d.Get("v2_host").(string),
-			AccessKey:             d.Get("access_key").(string),
-			SecretKey:             d.Get("secret_key").(string),
-			Profile:               d.Get("profile").(string),
-			SyncMode:              d.Get("sync_mode").(bool),
-			Region:                d.Get("region").(string),
-			MPRegion:              d.Get("mp_region").(string),
-			Arn:                   d.Get("arn").(string),
-			LfaArn:                d.Get("lfa_arn").(string),
-			LraArn:                d.Get("lra_arn").(string),
-			GraArn:                d.Get("gra_arn").(string),
-			AcctAdminArn:          d.Get("account_admin_arn").(string),
-			AuthType:              aws.AuthTypeIAMRole,
-			Protocol:              d.Get("protocol").(string),
-			Timeout:               d.Get("timeout").(int),
-			Headers:               hdrs,
-			SkipVerifyCertificate: d.Get("skip_verify_certificate").(bool),
-			Logging:               lc,
-			AuthFile:              d.Get("json_config_file").(string),
+	lm := map[string]uint32{
+		"quiet":   ngfw.LogQuiet,
+		"login":   ngfw.LogLogin,
+		"get":     ngfw.LogGet,
+		"patch":   ngfw.LogPatch,
+		"post":    ngfw.LogPost,
+		"put":     ngfw.LogPut,
+		"delete":  ngfw.LogDelete,
+		"action":  ngfw.LogAction,
+		"path":    ngfw.LogPath,
+		"send":    ngfw.LogSend,
+		"receive": ngfw.LogReceive,
+	}
 
-			CheckEnvironment: true,
-			Agent:            p.UserAgent("terraform-provider-cloudngfwaws", version),
-			Origin:           aws.OriginPA,
+	var hdrs map[string]string
+	hconfig := d.Get("headers").(map[string]interface{})
+	if len(hconfig) > 0 {
+		hdrs = make(map[string]string)
+		for key, val := range hconfig {
+			hdrs[key] = val.(string)
 		}
+	}
 
-		if err := con.Setup(); err != nil {
-			return nil, diag.FromErr(err)
+	if ll := d.Get("logging").([]interface{}); len(ll) > 0 {
+		for i := range ll {
+			s := ll[i].(string)
+			if v, ok := lm[s]; !ok {
+				return nil, fmt.Errorf("Unknown logging artifact specified: %s", s)
+			} else {
+				lc |= v
+			}
 		}
+	}
 
-		con.HttpClient.Transport = logging.NewTransport("CloudNgfwAws", con.HttpClient.Transport)
+	con := &aws.Client{
+		Host:                  d.Get("host").(string),
+		MPRegionHost:          d.Get("mp_region_host").(string),
+		V2Host:                d.Get("v2_host").(string),
+		AccessKey:             d.Get("access_key").(string),
+		SecretKey:             d.Get("secret_key").(string),
+		Profile:               d.Get("profile").(string),
+		SyncMode:              d.Get("sync_mode").(bool),
+		Region:                d.Get("region").(string),
+		MPRegion:              d.Get("mp_region").(string),
+		Arn:                   d.Get("arn").(string),
+		LfaArn:                d.Get("lfa_arn").(string),
+		LraArn:                d.Get("lra_arn").(string),
+		GraArn:                d.Get("gra_arn").(string),
+		AcctAdminArn:          d.Get("account_admin_arn").(string),
+		AuthType:              aws.AuthTypeIAMRole,
+		Protocol:              d.Get("protocol").(string),
+		Timeout:               d.Get("timeout").(int),
+		Headers:               hdrs,
+		SkipVerifyCertificate: d.Get("skip_verify_certificate").(bool),
+		Logging:               lc,
+		AuthFile:              d.Get("json_config_file").(string),
 
-		InitLogger(InfoLevel)
-		api.SetLogger(Logger)
+		CheckEnvironment: true,
+		Agent:            agent,
+		Origin:           aws.OriginPA,
+	}
 
-		apiClient := api.NewAPIClient(con, ctx, 5000, "", false)
-		api.Logger.Infof("sync_mode:%+v", apiClient.IsSyncModeEnabled(ctx))
-		return apiClient, nil
+	if err := con.Setup(); err != nil {
+		return nil, err
 	}
+
+	con.HttpClient.Transport = logging.NewTransport("CloudNgfwAws", con.HttpClient.Transport)
+
+	InitLogger(InfoLevel)
+	api.SetLogger(Logger)
+
+	return con, nil
 }
@@ -1,10 +1,12 @@
 package provider
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -45,12 +47,43 @@ var providerFactories = map[string]func() (*schema.Provider, error){
 	},
 }
 
+var protoV5ProviderFactories = map[string]func() (tfprotov5.ProviderServer, error){
+	"cloudngfwaws": func() (tfprotov5.ProviderServer, error) {
+		server, err := NewMuxServer(context.Background(), "dev")
+		if err != nil {
+			return nil, err
+		}
+		return server(), nil
+	},
+}
+
 func TestProvider(t *testing.T) {
 	if err := New("dev")().InternalValidate(); err != nil {
 		t.Fatalf("err: %s", err)
 	}
 }
 
+func TestMuxServer(t *testing.T) {
+	server, err := protoV5ProviderFactories["cloudngfwaws"]()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	res, err := server.GetProviderSchema(context.Background(), &tfprotov5.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	for _, d := range res.Diagnostics {
+		if d.Severity == tfprotov5.DiagnosticSeverityError {
+			t.Fatalf("%s: %s", d.Summary, d.Detail)
+		}
+	}
+
+	if _, ok := res.EphemeralResourceSchemas["cloudngfwaws_api_token"]; !ok {
+		t.Fatalf("cloudngfwaws_api_token ephemeral resource is missing")
+	}
+}
+
 func TestProvider_impl(t *testing.T) {
 	var _ *schema.Provider = New("dev")()
 }
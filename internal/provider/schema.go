@@ -179,7 +179,7 @@ func endpointsSchemaResource() *schema.Resource {
 				Type:        schema.TypeBool,
 				Optional:    true,
 				Computed:    true,
-				Description: "Enable egress NAT",
+				Description: "Enable egress NAT. This requires egress NAT to be enabled on the NGFW.",
 			},
 			"prefixes": {
 				Type:     schema.TypeList,
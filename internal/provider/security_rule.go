@@ -11,6 +11,9 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api"
+	"github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api/feed"
+	"github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api/fqdn"
+	"github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api/prefix"
 	"github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api/security"
 )
 
@@ -21,7 +24,7 @@ func dataSourceSecurityRule() *schema.Resource {
 
 		ReadContext: readSecurityRuleDataSource,
 
-		Schema: securityRuleSchema(false, nil),
+		Schema: securityRuleSchema(false, []string{"validate_references"}),
 	}
 }
 
@@ -103,10 +106,174 @@ func resourceSecurityRule() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
+		CustomizeDiff: validateSecurityRule,
+
 		Schema: securityRuleSchema(true, []string{ConfigTypeName}),
 	}
 }
 
+// validateSecurityRule checks the rule's references against the API.  Only
+// the params that have changed are checked, so that an unchanged rule doesn't
+// need any API calls to plan.
+func validateSecurityRule(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+	svc, ok := meta.(*api.ApiClient)
+	if !ok || svc == nil {
+		return nil
+	}
+
+	var countries []string
+	for _, key := range []string{"source.0.countries", "destination.0.countries"} {
+		if diff.HasChange(key) && diff.NewValueKnown(key) {
+			countries = append(countries, setToSlice(diff.Get(key))...)
+		}
+	}
+	if len(countries) > 0 {
+		codes, err := getSessionCache(svc).countryCodes(ctx, svc)
+		if err != nil {
+			return err
+		}
+		if err = checkCountryCodes(countries, codes); err != nil {
+			return err
+		}
+	}
+
+	if err := validateSecurityRuleApplications(ctx, diff, svc); err != nil {
+		return err
+	}
+
+	if !diff.Get("validate_references").(bool) || !diff.NewValueKnown(RulestackName) || !diff.NewValueKnown(ScopeName) {
+		return nil
+	}
+
+	stack := diff.Get(RulestackName).(string)
+	scope := diff.Get(ScopeName).(string)
+
+	refs := []struct {
+		key  string
+		kind string
+	}{
+		{"source.0.prefix_lists", "prefix list"},
+		{"destination.0.prefix_lists", "prefix list"},
+		{"destination.0.fqdn_lists", "fqdn list"},
+		{"source.0.feeds", "intelligent feed"},
+		{"destination.0.feeds", "intelligent feed"},
+		{"category.0.feeds", "intelligent feed"},
+	}
+
+	for _, ref := range refs {
+		if !diff.NewValueKnown(ref.key) || !(diff.HasChange(ref.key) || diff.HasChange("validate_references")) {
+			continue
+		}
+		for _, name := range setToSlice(diff.Get(ref.key)) {
+			var err error
+			switch ref.kind {
+			case "prefix list":
+				_, err = svc.ReadPrefixList(ctx, prefix.ReadInput{Rulestack: stack, Scope: scope, Name: name, Candidate: true})
+			case "fqdn list":
+				_, err = svc.ReadFqdn(ctx, fqdn.ReadInput{Rulestack: stack, Scope: scope, Name: name, Candidate: true})
+			default:
+				_, err = svc.ReadFeed(ctx, feed.ReadInput{Rulestack: stack, Scope: scope, Name: name, Candidate: true})
+			}
+			if err != nil {
+				if isObjectNotFound(err) {
+					return fmt.Errorf("%s %q referenced in %s does not exist in rulestack %q", ref.kind, name, ref.key, stack)
+				}
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkCountryCodes returns an error for the first of the given countries that
+// isn't one of the known country codes.
+func checkCountryCodes(countries []string, codes map[string]string) error {
+	for _, code := range countries {
+		if _, ok := codes[code]; !ok {
+			return fmt.Errorf("unknown country code %q, see the cloudngfwaws_country data source for valid codes", code)
+		}
+	}
+
+	return nil
+}
+
+// validateSecurityRuleApplications checks changed applications against the
+// latest AppId version, as well as the rulestack's minimum AppId version if it
+// has one.
+func validateSecurityRuleApplications(ctx context.Context, diff *schema.ResourceDiff, svc *api.ApiClient) error {
+	if !diff.HasChange("applications") || !diff.NewValueKnown("applications") {
+		return nil
+	}
+
+	var apps []string
+	for _, x := range setToSlice(diff.Get("applications")) {
+		if x != "any" {
+			apps = append(apps, x)
+		}
+	}
+	if len(apps) == 0 {
+		return nil
+	}
+
+	cache := getSessionCache(svc)
+	listing, err := cache.appIdVersions(ctx, svc)
+	if err != nil {
+		return err
+	}
+	if len(listing) == 0 {
+		return nil
+	}
+	versions := []string{listing[len(listing)-1]}
+
+	if diff.NewValueKnown(RulestackName) && diff.NewValueKnown(ScopeName) {
+		v, err := cache.rulestackMinAppIdVersion(ctx, svc, diff.Get(ScopeName).(string), diff.Get(RulestackName).(string))
+		if err != nil {
+			return err
+		}
+		if v != "" && v != versions[0] {
+			versions = append(versions, v)
+		}
+	}
+
+	catalogs := make([][]string, 0, len(versions))
+	for _, v := range versions {
+		names, err := cache.appIdApplications(ctx, svc, v)
+		if err != nil {
+			return err
+		}
+		catalogs = append(catalogs, names)
+	}
+
+	return checkApplications(apps, versions, catalogs)
+}
+
+// checkApplications returns an error for the first of the given applications
+// that isn't in any of the catalogs of the given AppId versions, suggesting
+// the closest match in the first (latest) version.
+func checkApplications(apps, versions []string, catalogs [][]string) error {
+	known := make(map[string]bool)
+	for _, names := range catalogs {
+		for _, x := range names {
+			known[x] = true
+		}
+	}
+	if len(known) == 0 {
+		return nil
+	}
+
+	for _, x := range apps {
+		if !known[x] {
+			if guess := closestMatch(x, catalogs[0]); guess != "" {
+				return fmt.Errorf("unknown application %q in AppId version %s, did you mean %q?", x, strings.Join(versions, " / "), guess)
+			}
+			return fmt.Errorf("unknown application %q in AppId version %s", x, strings.Join(versions, " / "))
+		}
+	}
+
+	return nil
+}
+
 func createSecurityRule(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	svc := meta.(*api.ApiClient)
 	o := loadSecurityRule(d)
@@ -265,7 +432,8 @@ func securityRuleSchema(isResource bool, rmKeys []string) map[string]*schema.Sch
 						Optional:    true,
 						Description: "List of CIDRs.",
 						Elem: &schema.Schema{
-							Type: schema.TypeString,
+							Type:         schema.TypeString,
+							ValidateFunc: validateCidr,
 						},
 					},
 					"countries": {
@@ -313,7 +481,8 @@ func securityRuleSchema(isResource bool, rmKeys []string) map[string]*schema.Sch
 						Optional:    true,
 						Description: "List of CIDRs.",
 						Elem: &schema.Schema{
-							Type: schema.TypeString,
+							Type:         schema.TypeString,
+							ValidateFunc: validateCidr,
 						},
 					},
 					"countries": {
@@ -428,6 +597,11 @@ func securityRuleSchema(isResource bool, rmKeys []string) map[string]*schema.Sch
 			ValidateFunc: validation.StringInSlice(decryption_values, false),
 		},
 		TagsName: tagsSchema(true),
+		"validate_references": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Description: "Verify during plan that the referenced prefix lists, FQDN lists, and intelligent feeds exist in the rulestack. Only enable this if those objects are not created in the same plan as this rule.",
+		},
 		"update_token": {
 			Type:        schema.TypeString,
 			Computed:    true,
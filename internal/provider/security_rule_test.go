@@ -1,14 +1,18 @@
 package provider
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"testing"
 
+	"github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api"
 	"github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api/security"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 // Data source.
@@ -283,3 +287,81 @@ resource "cloudngfwaws_security_rule" "test" {
 
 	return buf.String()
 }
+
+func TestCheckCountryCodes(t *testing.T) {
+	codes := map[string]string{"US": "United States", "DE": "Germany"}
+
+	table := []struct {
+		countries []string
+		errors    bool
+	}{
+		{nil, false},
+		{[]string{"US"}, false},
+		{[]string{"US", "DE"}, false},
+		{[]string{"US", "XX"}, true},
+		{[]string{"us"}, true},
+	}
+
+	for _, tc := range table {
+		if err := checkCountryCodes(tc.countries, codes); (err != nil) != tc.errors {
+			t.Errorf("checkCountryCodes(%v): expected error %t, got %v", tc.countries, tc.errors, err)
+		}
+	}
+}
+
+func TestValidateSecurityRuleUnchanged(t *testing.T) {
+	r := resourceSecurityRule()
+	raw := map[string]interface{}{
+		RulestackName: "rs",
+		"priority":    1,
+		"name":        "rule",
+		"source": []interface{}{
+			map[string]interface{}{"countries": []interface{}{"XX"}},
+		},
+		"destination": []interface{}{
+			map[string]interface{}{"prefix_lists": []interface{}{"missing"}},
+		},
+		"applications":        []interface{}{"web-browsng"},
+		"category":            []interface{}{map[string]interface{}{}},
+		"action":              "Allow",
+		"validate_references": true,
+	}
+
+	d := schema.TestResourceDataRaw(t, r.Schema, raw)
+	d.SetId("rule")
+
+	// The client isn't set up, so any API call would fail.
+	if _, err := r.Diff(context.Background(), d.State(), terraform.NewResourceConfigRaw(raw), &api.ApiClient{}); err != nil {
+		t.Fatalf("unexpected error planning an unchanged rule: %s", err)
+	}
+}
+
+func TestCheckApplications(t *testing.T) {
+	latest := []string{"ssl", "web-browsing", "dns"}
+	minimum := []string{"ssl", "legacy-app"}
+
+	table := []struct {
+		name     string
+		apps     []string
+		versions []string
+		catalogs [][]string
+		err      string
+	}{
+		{"known", []string{"ssl", "dns"}, []string{"8800"}, [][]string{latest}, ""},
+		{"suggestion", []string{"web-browsng"}, []string{"8800"}, [][]string{latest}, `unknown application "web-browsng" in AppId version 8800, did you mean "web-browsing"?`},
+		{"no suggestion", []string{"bittorrent"}, []string{"8800"}, [][]string{latest}, `unknown application "bittorrent" in AppId version 8800`},
+		{"min version app", []string{"legacy-app"}, []string{"8800", "8700"}, [][]string{latest, minimum}, ""},
+		{"unknown in both versions", []string{"legacy-ap"}, []string{"8800", "8700"}, [][]string{latest, minimum}, `unknown application "legacy-ap" in AppId version 8800 / 8700`},
+		{"empty catalog", []string{"anything"}, []string{"8800"}, [][]string{nil}, ""},
+	}
+
+	for _, tc := range table {
+		err := checkApplications(tc.apps, tc.versions, tc.catalogs)
+		switch {
+		case tc.err == "" && err != nil:
+			t.Errorf("%s: unexpected error: %s", tc.name, err)
+		case tc.err != "" && (err == nil || err.Error() != tc.err):
+			t.Errorf("%s: expected error %q, got %v", tc.name, tc.err, err)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api"
+	"github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api/appid"
+	"github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api/stack"
+	"github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/ngfw/aws"
+)
+
+// sessionCache holds API lookups that don't change over the lifetime of a
+// provider session, so plan-time validation doesn't repeat them per resource.
+// The lock is not held during API calls, so concurrent misses may both fetch.
+type sessionCache struct {
+	con *aws.Client
+
+	mu           sync.Mutex
+	countries    map[string]string
+	versions     []string
+	applications map[string][]string
+	appDetails   map[string]appid.ApplicationDetails
+	minAppIds    map[string]string
+}
+
+var sessionCaches sync.Map
+
+func getSessionCache(svc *api.ApiClient) *sessionCache {
+	ans, _ := sessionCaches.LoadOrStore(svc, &sessionCache{})
+	return ans.(*sessionCache)
+}
+
+// countryCodes returns the country codes (as the key) and their descriptions.
+func (c *sessionCache) countryCodes(ctx context.Context, svc *api.ApiClient) (map[string]string, error) {
+	c.mu.Lock()
+	ans := c.countries
+	c.mu.Unlock()
+	if ans != nil {
+		return ans, nil
+	}
+
+	listing, err := listCountries(ctx, svc, 100, "")
+	if err != nil {
+		return nil, err
+	}
+
+	ans = make(map[string]string, len(listing))
+	for _, x := range listing {
+		ans[x.Code] = x.Description
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.countries = ans
+
+	return ans, nil
+}
+
+// appIdVersions returns all AppId versions, sorted oldest to newest.
+func (c *sessionCache) appIdVersions(ctx context.Context, svc *api.ApiClient) ([]string, error) {
+	c.mu.Lock()
+	ans := c.versions
+	c.mu.Unlock()
+	if ans != nil {
+		return ans, nil
+	}
+
+	listing, err := listAppIdVersions(ctx, svc, 100, "")
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.versions = listing
+
+	return listing, nil
+}
+
+// appIdApplications returns the applications in the given AppId version.
+func (c *sessionCache) appIdApplications(ctx context.Context, svc *api.ApiClient, version string) ([]string, error) {
+	c.mu.Lock()
+	ans, ok := c.applications[version]
+	c.mu.Unlock()
+	if ok {
+		return ans, nil
+	}
+
+	listing, err := listApplications(ctx, svc, version, 100, "")
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.applications == nil {
+		c.applications = make(map[string][]string)
+	}
+	c.applications[version] = listing
+
+	return listing, nil
+}
+
+// rulestackMinAppIdVersion returns the minimum AppId version of the given
+// rulestack's candidate config, or an empty string if it doesn't exist.
+func (c *sessionCache) rulestackMinAppIdVersion(ctx context.Context, svc *api.ApiClient, scope, name string) (string, error) {
+	key := buildRulestackId(scope, name)
+
+	c.mu.Lock()
+	ans, ok := c.minAppIds[key]
+	c.mu.Unlock()
+	if ok {
+		return ans, nil
+	}
+
+	res, err := svc.ReadRuleStack(ctx, stack.ReadInput{
+		Name:      name,
+		Scope:     scope,
+		Candidate: true,
+	})
+	if err != nil {
+		if !isObjectNotFound(err) {
+			return "", err
+		}
+	} else if res.Response != nil && res.Response.Candidate != nil {
+		ans = res.Response.Candidate.MinimumAppIdVersion
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.minAppIds == nil {
+		c.minAppIds = make(map[string]string)
+	}
+	c.minAppIds[key] = ans
+
+	return ans, nil
+}
+
+// appIdApplication returns the details of an application in the given AppId
+// version.
+func (c *sessionCache) appIdApplication(ctx context.Context, version, name string) (appid.ApplicationDetails, error) {
+	if c.con == nil {
+		return appid.ApplicationDetails{}, fmt.Errorf("the provider has not been configured")
+	}
+
+	key := version + IdSeparator + name
+	c.mu.Lock()
+	ans, ok := c.appDetails[key]
+	c.mu.Unlock()
+	if ok {
+		return ans, nil
+	}
+
+	res, err := c.con.ReadApplication(ctx, version, name)
+	if err != nil {
+		return appid.ApplicationDetails{}, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.appDetails == nil {
+		c.appDetails = make(map[string]appid.ApplicationDetails)
+	}
+	c.appDetails[key] = res.Response.Details
+
+	return res.Response.Details, nil
+}
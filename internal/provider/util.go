@@ -2,6 +2,9 @@ package provider
 
 import (
 	"fmt"
+	"net/netip"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/paloaltonetworks/cloud-ngfw-aws-go/v2/api/firewall"
@@ -201,3 +204,166 @@ func GetEpId(curEpMap map[string]firewall.EndpointConfig, subnetId string) strin
 	reqEp := curEpMap[subnetId]
 	return reqEp.EndpointId
 }
+
+// normalizeCidr returns the canonical form of the given CIDR or IP address:
+// host bits are masked off CIDRs, and addresses are rendered in their shortest
+// form.
+func normalizeCidr(v string) (string, error) {
+	if strings.Contains(v, "/") {
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(v))
+		if err != nil {
+			return "", fmt.Errorf("invalid CIDR %q", v)
+		}
+		return prefix.Masked().String(), nil
+	}
+
+	addr, err := netip.ParseAddr(strings.TrimSpace(v))
+	if err != nil {
+		return "", fmt.Errorf("invalid IP address %q", v)
+	}
+	return addr.String(), nil
+}
+
+// normalizeCidrs canonicalizes, deduplicates, and sorts the given CIDRs.
+func normalizeCidrs(list []string) ([]string, error) {
+	seen := make(map[string]bool, len(list))
+	ans := make([]string, 0, len(list))
+	for _, v := range list {
+		cidr, err := normalizeCidr(v)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[cidr] {
+			seen[cidr] = true
+			ans = append(ans, cidr)
+		}
+	}
+
+	sort.Slice(ans, func(i, j int) bool {
+		a, b := cidrSortKey(ans[i]), cidrSortKey(ans[j])
+		if c := a.Addr().Compare(b.Addr()); c != 0 {
+			return c < 0
+		}
+		return a.Bits() < b.Bits()
+	})
+
+	return ans, nil
+}
+
+func cidrSortKey(v string) netip.Prefix {
+	if prefix, err := netip.ParsePrefix(v); err == nil {
+		return prefix
+	}
+	addr := netip.MustParseAddr(v)
+	return netip.PrefixFrom(addr, addr.BitLen())
+}
+
+// fqdnLabelRegex allows a leading underscore, as used by service records such
+// as _dmarc.example.com.
+var fqdnLabelRegex = regexp.MustCompile(`^_?[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?$`)
+
+// validateFqdn checks that the given value is a FQDN, optionally prefixed with
+// a single "*." wildcard label.
+func validateFqdn(v string) error {
+	name := strings.TrimSuffix(v, ".")
+	if name == "" {
+		return fmt.Errorf("FQDN cannot be empty")
+	}
+	if len(name) > 253 {
+		return fmt.Errorf("FQDN %q is longer than 253 characters", v)
+	}
+
+	labels := strings.Split(name, ".")
+	if labels[0] == "*" {
+		labels = labels[1:]
+		if len(labels) < 2 {
+			return fmt.Errorf("wildcard FQDN %q must have at least two labels after the wildcard", v)
+		}
+	}
+
+	for _, label := range labels {
+		if strings.Contains(label, "*") {
+			return fmt.Errorf("FQDN %q may only have a wildcard as the whole leftmost label", v)
+		}
+		if len(label) > 63 || !fqdnLabelRegex.MatchString(label) {
+			return fmt.Errorf("FQDN %q has an invalid label %q", v, label)
+		}
+	}
+
+	return nil
+}
+
+// validateCidr is a ValidateFunc for a CIDR or IP address, also allowing the
+// special value "any".
+func validateCidr(v interface{}, k string) ([]string, []error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, []error{fmt.Errorf("expected type of %s to be string", k)}
+	}
+
+	if s == "any" {
+		return nil, nil
+	}
+
+	if _, err := normalizeCidr(s); err != nil {
+		return nil, []error{fmt.Errorf("%s: %s", k, err)}
+	}
+
+	return nil, nil
+}
+
+// closestMatch returns the value in choices with the smallest edit distance
+// to s, or an empty string if none is within a third of the length of s.
+func closestMatch(s string, choices []string) string {
+	var ans string
+	best := len([]rune(s))/3 + 1
+	for _, x := range choices {
+		dist := levenshtein(strings.ToLower(s), strings.ToLower(x))
+		if dist < best {
+			ans, best = x, dist
+		}
+	}
+
+	return ans
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// stringSliceDifference returns the sorted values in a that are not in b.
+func stringSliceDifference(a, b []string) []string {
+	lookup := make(map[string]bool, len(b))
+	for _, x := range b {
+		lookup[x] = true
+	}
+
+	ans := make([]string, 0)
+	for _, x := range a {
+		if !lookup[x] {
+			ans = append(ans, x)
+		}
+	}
+	sort.Strings(ans)
+
+	return ans
+}
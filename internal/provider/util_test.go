@@ -0,0 +1,128 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClosestMatch(t *testing.T) {
+	choices := []string{"ssl", "web-browsing", "dns", "facebook-base"}
+
+	table := []struct {
+		s    string
+		want string
+	}{
+		{"web-browsng", "web-browsing"},
+		{"Facebook-Base", "facebook-base"},
+		{"dnz", "dns"},
+		{"xyz", ""},
+		{"bittorrent", ""},
+		{"", ""},
+	}
+
+	for _, tc := range table {
+		if got := closestMatch(tc.s, choices); got != tc.want {
+			t.Errorf("closestMatch(%q): expected %q, got %q", tc.s, tc.want, got)
+		}
+	}
+
+	if got := closestMatch("ssl", nil); got != "" {
+		t.Errorf("closestMatch with no choices: expected \"\", got %q", got)
+	}
+}
+
+func TestStringSliceDifference(t *testing.T) {
+	table := []struct {
+		a, b []string
+		want []string
+	}{
+		{[]string{"c", "a", "b"}, []string{"b"}, []string{"a", "c"}},
+		{[]string{"a"}, []string{"a"}, []string{}},
+		{nil, []string{"a"}, []string{}},
+		{[]string{"b", "a"}, nil, []string{"a", "b"}},
+	}
+
+	for _, tc := range table {
+		got := stringSliceDifference(tc.a, tc.b)
+		if len(got) != len(tc.want) {
+			t.Errorf("stringSliceDifference(%v, %v): expected %v, got %v", tc.a, tc.b, tc.want, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("stringSliceDifference(%v, %v): expected %v, got %v", tc.a, tc.b, tc.want, got)
+				break
+			}
+		}
+	}
+}
+
+func TestNormalizeCidr(t *testing.T) {
+	table := []struct {
+		v    string
+		want string
+		ok   bool
+	}{
+		{"10.1.2.3/8", "10.0.0.0/8", true},
+		{" 192.168.1.0/24 ", "192.168.1.0/24", true},
+		{"10.1.2.3", "10.1.2.3", true},
+		{"2001:DB8::1/32", "2001:db8::/32", true},
+		{"10.0.0.0/33", "", false},
+		{"not-an-ip", "", false},
+	}
+
+	for _, tc := range table {
+		got, err := normalizeCidr(tc.v)
+		if tc.ok && (err != nil || got != tc.want) {
+			t.Errorf("normalizeCidr(%q): expected %q, got %q (err %v)", tc.v, tc.want, got, err)
+		} else if !tc.ok && err == nil {
+			t.Errorf("normalizeCidr(%q): expected an error, got %q", tc.v, got)
+		}
+	}
+
+	got, err := normalizeCidrs([]string{"10.0.0.0/8", "10.1.0.0/16", "10.0.0.1/8", "9.0.0.0/8"})
+	if err != nil {
+		t.Fatalf("normalizeCidrs: %s", err)
+	}
+	want := []string{"9.0.0.0/8", "10.0.0.0/8", "10.1.0.0/16"}
+	if len(got) != len(want) {
+		t.Fatalf("normalizeCidrs: expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("normalizeCidrs: expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestValidateFqdn(t *testing.T) {
+	table := []struct {
+		v  string
+		ok bool
+	}{
+		{"www.example.com", true},
+		{"www.example.com.", true},
+		{"*.example.com", true},
+		{"*.com", false},
+		{"www.*.com", false},
+		{"-bad.example.com", false},
+		{"_dmarc.example.com", true},
+		{"_sip._tcp.example.com", true},
+		{"*._tcp.example.com", true},
+		{"__bad.example.com", false},
+		{"_.example.com", false},
+		{"bad_.example.com", false},
+		{strings.Repeat("a", 63) + ".com", true},
+		{"_" + strings.Repeat("a", 63) + ".com", false},
+		{"", false},
+	}
+
+	for _, tc := range table {
+		err := validateFqdn(tc.v)
+		if tc.ok && err != nil {
+			t.Errorf("validateFqdn(%q): unexpected error: %s", tc.v, err)
+		} else if !tc.ok && err == nil {
+			t.Errorf("validateFqdn(%q): expected an error", tc.v)
+		}
+	}
+}